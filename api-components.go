@@ -2,23 +2,58 @@ package powerwall
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 type SimpleDeviceStatus struct {
+	PartNumber        string                   `json:"partNumber,omitzero"`
+	SerialNumber      string                   `json:"serialNumber,omitzero"`
 	BatteryEnergy     int                      `json:"battery"`
 	BatteryFullEnergy int                      `json:"batteryFull"`
 	PowerBattery      float64                  `json:"powerBattery"`
 	PowerSolar        float64                  `json:"powerSolar"`
 	Freq              float64                  `json:"freq"`
-	Voltage           float64                  `json:"voltage"`
-	MPPT              []SimpleDeviceMPPTStatus `json:"mppt"`
+	Voltage           float64                  `json:"voltage"` // line-to-line if split-phase, otherwise line-to-neutral: see Wiring
+	Wiring            Wiring                   `json:"wiring"`
+	VoltageAN         float64                  `json:"voltageAN"`
+	VoltageBN         float64                  `json:"voltageBN"`
+	VoltageAB         float64                  `json:"voltageAB"`
+	State             PCHState                 `json:"state"`
+	ACMode            ACMode                   `json:"acMode"`
+	MPPT              []SimpleDeviceMPPTStatus `json:"mppt"` // from all PCH
+	PCH               []SimpleDevicePCHStatus  `json:"pch,omitzero"`
+}
+
+// SimpleDevicePCHStatus is the status of a single PCH (power conversion hardware) inside a device.
+// The totals on [SimpleDeviceStatus] are aggregated from these.
+type SimpleDevicePCHStatus struct {
+	PartNumber   string                   `json:"partNumber,omitzero"`
+	SerialNumber string                   `json:"serialNumber,omitzero"`
+	PowerBattery float64                  `json:"powerBattery"`
+	PowerSolar   float64                  `json:"powerSolar"`
+	Freq         float64                  `json:"freq"`
+	Voltage      float64                  `json:"voltage"` // line-to-line if split-phase, otherwise line-to-neutral: see Wiring
+	Wiring       Wiring                   `json:"wiring"`
+	VoltageAN    float64                  `json:"voltageAN"`
+	VoltageBN    float64                  `json:"voltageBN"`
+	VoltageAB    float64                  `json:"voltageAB"`
+	State        PCHState                 `json:"state"`
+	ACMode       ACMode                   `json:"acMode"`
+	MPPT         []SimpleDeviceMPPTStatus `json:"mppt"`
+}
+
+// FormatVoltage describes the device's voltages in the way that fits its wiring, e.g., "241.5v L-L (120.5v + 121.0v L-N)"
+// for split-phase, or "230.0v" for single-phase.
+func (s *SimpleDeviceStatus) FormatVoltage() string {
+	return formatVoltage(s.Wiring, s.VoltageAN, s.VoltageBN, s.VoltageAB)
 }
 
 type SimpleDeviceMPPTStatus struct {
 	Current float64 `json:"c"`
 	Voltage float64 `json:"v"`
+	State   PVState `json:"state"`
 }
 
 func (s SimpleDeviceMPPTStatus) FormatPower() (out string) {
@@ -29,14 +64,17 @@ func (s SimpleDeviceMPPTStatus) FormatPower() (out string) {
 	return fmt.Sprintf("%.0fw", w)
 }
 
-type rawSignal struct {
-	Name  string   `json:"name"`
-	Value *float64 `json:"value,omitzero"`
+// Signal is a single named value reported by a component.
+type Signal struct {
+	Name      string   `json:"name"`
+	Value     *float64 `json:"value,omitzero"`
+	TextValue *string  `json:"textValue,omitzero"`
 }
 
-type rawSignalArray []rawSignal
+type Signals []Signal
 
-func (ra rawSignalArray) ToMap() map[string]float64 {
+// ToMap returns the numeric signals by name.
+func (ra Signals) ToMap() map[string]float64 {
 	signalMap := make(map[string]float64)
 	for _, s := range ra {
 		if s.Value != nil {
@@ -46,47 +84,139 @@ func (ra rawSignalArray) ToMap() map[string]float64 {
 	return signalMap
 }
 
-// GetSimpleDeviceStatus reads a [SimpleDeviceStatus] struct from an individual device.
-// For a PW3, this contains its charge etc plus the status of its MPPTs.
-// You must pass individual DINs (get from [SimpleStatus]).
-func GetSimpleDeviceStatus(ctx context.Context, td *TEDApi, din string) (status *SimpleDeviceStatus, err error) {
-	var out []byte
-	out, err = td.QueryDevice(ctx, QueryComponents, din)
-	if err != nil {
-		return
+// Text returns the text value of the named signal, or its numeric value formatted as a string.
+func (ra Signals) Text(name string) string {
+	for _, s := range ra {
+		if s.Name != name {
+			continue
+		}
+		if s.TextValue != nil {
+			return *s.TextValue
+		} else if s.Value != nil {
+			return strconv.FormatFloat(*s.Value, 'f', -1, 64)
+		}
 	}
+	return ""
+}
+
+// Component is a single component (e.g., PCH, BMS) within a device.
+type Component struct {
+	PartNumber   string `json:"partNumber"`
+	SerialNumber string `json:"serialNumber"`
+	ActiveAlerts []struct {
+		Name string `json:"name"`
+	} `json:"activeAlerts"`
+	Signals Signals `json:"signals"`
+}
+
+// ComponentsResponse is the decoded response to [QueryComponents].
+type ComponentsResponse struct {
+	Components struct {
+		PWS   []Component `json:"pws"`
+		PCH   []Component `json:"pch"`
+		BMS   []Component `json:"bms"`
+		HVP   []Component `json:"hvp"`
+		BAGGR []Component `json:"baggr"`
+	} `json:"components"`
+}
+
+// queryComponents runs [QueryComponents] against the given device.
+func queryComponents(ctx context.Context, td *TEDApi, din string) (r *ComponentsResponse, err error) {
+	return QueryInto[ComponentsResponse](ctx, td, QueryComponents, din)
+}
 
-	type componentPart struct {
-		ActiveAlerts []struct {
-			Name string `json:"name"`
-		} `json:"activeAlerts"`
-		Signals rawSignalArray `json:"signals"`
+// decodeSignalChars reassembles a string that's been split across several numeric signals.
+// Each signal holds up to 7 characters of 7-bit ASCII, first character in the lowest bits (so it fits in a float64).
+// The result stops at the first NUL, and surrounding spaces are trimmed.
+func decodeSignalChars(signalMap map[string]float64, names ...string) string {
+	var out []byte
+	for _, name := range names {
+		v, ok := signalMap[name]
+		if !ok {
+			break
+		}
+		bits := uint64(v)
+		for range 7 {
+			out = append(out, byte(bits&0x7f))
+			bits >>= 7
+		}
 	}
+	s, _, _ := strings.Cut(string(out), "\x00")
+	return strings.TrimSpace(s)
+}
+
+// pchPartSerial decodes the part and serial number from a PCH's signals.
+func pchPartSerial(signalMap map[string]float64) (part, serial string) {
+	part = decodeSignalChars(signalMap, "PCH_packagePartNumber_1_7", "PCH_packagePartNumber_8_14", "PCH_packagePartNumber_15_20")
+	serial = decodeSignalChars(signalMap, "PCH_packageSerialNumber_1_7", "PCH_packageSerialNumber_8_14")
+	return part, serial
+}
+
+// decodePCH reads the status of a single PCH from its signals.
+func decodePCH(signals Signals) (pch SimpleDevicePCHStatus) {
+	signalMap := signals.ToMap()
+	pch.PowerSolar = signalMap["PCH_SlowPvPowerSum"]
+	pch.PowerBattery = signalMap["PCH_BatteryPower"]
+	pch.Freq = signalMap["PCH_AcFrequency"]
+	pch.VoltageAN = signalMap["PCH_AcVoltageAN"]
+	pch.VoltageBN = signalMap["PCH_AcVoltageBN"]
+	pch.VoltageAB = signalMap["PCH_AcVoltageAB"]
+	pch.Wiring = detectDeviceWiring(pch.VoltageAN, pch.VoltageBN, pch.VoltageAB)
+	pch.Voltage = deviceVoltage(pch.Wiring, pch.VoltageAN, pch.VoltageBN, pch.VoltageAB)
+	pch.State = PCHState{signals.enumSignal("PCH_State")}
+	pch.ACMode = ACMode{signals.enumSignal("PCH_AcMode")}
+	pch.PartNumber, pch.SerialNumber = pchPartSerial(signalMap)
+
+	// in Australia this is sold as 3, but they're just pairs of two doing half duty each
+	for i := range 6 {
+		char := ('A' + i)
+		current, ok1 := signalMap[fmt.Sprintf("PCH_PvCurrent%c", char)]
+		voltage, ok2 := signalMap[fmt.Sprintf("PCH_PvVoltage%c", char)]
+		if !ok1 && !ok2 {
+			break
+		}
 
-	type componentsResponse struct {
-		Components struct {
-			BMS []componentPart `json:"bms"`
-			PCH []componentPart `json:"pch"`
-		} `json:"components"`
+		pch.MPPT = append(pch.MPPT, SimpleDeviceMPPTStatus{
+			Current: current,
+			Voltage: voltage,
+			State:   PVState{signals.enumSignal(fmt.Sprintf("PCH_PvState_%c", char))},
+		})
 	}
 
-	var r componentsResponse
-	json.Unmarshal(out, &r)
+	return pch
+}
 
-	// var m map[string]any
-	// json.Unmarshal(out, &m)
-	// b, _ := json.MarshalIndent(m, "", "  ")
-	// log.Printf("RAW components: %s => %s", din, string(b))
+// GetSimpleDeviceStatus reads a [SimpleDeviceStatus] struct from an individual device.
+// For a PW3, this contains its charge etc plus the status of its MPPTs.
+// You must pass individual DINs (get from [SimpleStatus]).
+func GetSimpleDeviceStatus(ctx context.Context, td *TEDApi, din string) (status *SimpleDeviceStatus, err error) {
+	r, err := queryComponents(ctx, td, din)
+	if err != nil {
+		return nil, err
+	}
+	return simpleDeviceStatus(r)
+}
 
+// simpleDeviceStatus summarizes a device's components, aggregating over any number of PCHs.
+func simpleDeviceStatus(r *ComponentsResponse) (status *SimpleDeviceStatus, err error) {
 	if len(r.Components.BMS) < 1 {
 		return nil, fmt.Errorf("could not get BMS data from device")
 	}
 
-	// battery energy
-	bmsMap := r.Components.BMS[0].Signals.ToMap()
-	energyKw, ok1 := bmsMap["BMS_nominalEnergyRemaining"]
-	fullEnergyKw, ok2 := bmsMap["BMS_nominalFullPackEnergy"]
-	if !ok1 || !ok2 {
+	// battery energy, summed over every BMS that reports it
+	var energyKw, fullEnergyKw float64
+	var reported bool
+	for _, bms := range r.Components.BMS {
+		bmsMap := bms.Signals.ToMap()
+		energy, ok1 := bmsMap["BMS_nominalEnergyRemaining"]
+		fullEnergy, ok2 := bmsMap["BMS_nominalFullPackEnergy"]
+		if ok1 && ok2 {
+			energyKw += energy
+			fullEnergyKw += fullEnergy
+			reported = true
+		}
+	}
+	if !reported {
 		return nil, fmt.Errorf("could not get battery energy data")
 	}
 
@@ -96,36 +226,27 @@ func GetSimpleDeviceStatus(ctx context.Context, td *TEDApi, din string) (status
 	}
 
 	// solar status (PW3 only probably)
-
-	switch len(r.Components.PCH) {
-	case 0:
-		// nothing
-	case 1:
-		// ok
-		only := r.Components.PCH[0].Signals.ToMap()
-
-		status.PowerSolar = only["PCH_SlowPvPowerSum"]
-		status.PowerBattery = only["PCH_BatteryPower"]
-		status.Freq = only["PCH_AcFrequency"]
-		status.Voltage = only["PCH_AcVoltageAB"] // also has to N from A/B
-
-		// in Australia this is sold as 3, but they're just pairs of two doing half duty each
-		for i := range 6 {
-			char := ('A' + i)
-			current, ok1 := only[fmt.Sprintf("PCH_PvCurrent%c", char)]
-			voltage, ok2 := only[fmt.Sprintf("PCH_PvVoltage%c", char)]
-			if !ok1 && !ok2 {
-				break
-			}
-
-			status.MPPT = append(status.MPPT, SimpleDeviceMPPTStatus{
-				Current: current,
-				Voltage: voltage,
-			})
+	// there's usually exactly one PCH, but aggregate over any number; the first provides AC status
+	for i, part := range r.Components.PCH {
+		pch := decodePCH(part.Signals)
+		status.PCH = append(status.PCH, pch)
+
+		status.PowerSolar += pch.PowerSolar
+		status.PowerBattery += pch.PowerBattery
+		status.MPPT = append(status.MPPT, pch.MPPT...)
+
+		if i == 0 {
+			status.PartNumber = pch.PartNumber
+			status.SerialNumber = pch.SerialNumber
+			status.Freq = pch.Freq
+			status.Voltage = pch.Voltage
+			status.Wiring = pch.Wiring
+			status.VoltageAN = pch.VoltageAN
+			status.VoltageBN = pch.VoltageBN
+			status.VoltageAB = pch.VoltageAB
+			status.State = pch.State
+			status.ACMode = pch.ACMode
 		}
-
-	case 2:
-		return nil, fmt.Errorf("got multiple PCH: %v", len(r.Components.PCH))
 	}
 
 	return status, nil
@@ -0,0 +1,104 @@
+package powerwall
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// packSignalChars packs up to 7 characters as decodeSignalChars expects, first character in the lowest bits.
+func packSignalChars(s string) float64 {
+	var v uint64
+	for i := len(s) - 1; i >= 0; i-- {
+		v = v<<7 | uint64(s[i]&0x7f)
+	}
+	return float64(v)
+}
+
+func TestDecodeSignalChars(t *testing.T) {
+	names := []string{"a", "b", "c"}
+	tests := []struct {
+		name      string
+		signalMap map[string]float64
+		want      string
+	}{
+		{"empty", map[string]float64{}, ""},
+		{"part number", map[string]float64{
+			"a": packSignalChars("1707000"),
+			"b": packSignalChars("-21-K  "),
+			"c": packSignalChars(""),
+		}, "1707000-21-K"},
+		{"serial", map[string]float64{
+			"a": packSignalChars("TG12345"),
+			"b": packSignalChars("6789012"),
+		}, "TG123456789012"},
+		{"stops at missing chunk", map[string]float64{
+			"a": packSignalChars("ABCDEFG"),
+			"c": packSignalChars("XYZ"),
+		}, "ABCDEFG"},
+		{"stops at NUL", map[string]float64{
+			"a": packSignalChars("AB"),
+			"b": packSignalChars("CD"),
+		}, "AB"},
+		{"first char is lowest bits", map[string]float64{"a": 0x41 | 0x42<<7}, "AB"},
+	}
+	for _, tt := range tests {
+		got := decodeSignalChars(tt.signalMap, names...)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSimpleDeviceStatusAggregates(t *testing.T) {
+	raw, err := os.ReadFile("testdata/components-two-pch.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var r ComponentsResponse
+	if err := json.Unmarshal(raw, &r); err != nil {
+		t.Fatal(err)
+	}
+
+	status, err := simpleDeviceStatus(&r)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.BatteryEnergy != 7750 || status.BatteryFullEnergy != 26750 {
+		t.Errorf("got battery %d/%d, want 7750/26750", status.BatteryEnergy, status.BatteryFullEnergy)
+	}
+	if status.PowerSolar != 4500 || status.PowerBattery != -1500 {
+		t.Errorf("got solar=%v battery=%v, want 4500/-1500", status.PowerSolar, status.PowerBattery)
+	}
+	if len(status.PCH) != 2 {
+		t.Fatalf("got %d PCH, want 2", len(status.PCH))
+	}
+	if status.PCH[0].PowerSolar != 3000 || status.PCH[1].PowerSolar != 1500 {
+		t.Errorf("got per-PCH solar %v, %v", status.PCH[0].PowerSolar, status.PCH[1].PowerSolar)
+	}
+
+	// MPPTs from every PCH, in order
+	var states []string
+	for _, m := range status.MPPT {
+		states = append(states, m.State.String())
+	}
+	if got := strings.Join(states, ","); got != "Active,Active,Active,Disabled,Fault" {
+		t.Errorf("got MPPT states %s", got)
+	}
+
+	// AC status comes from the first PCH
+	if status.State.String() != "Active" || status.Freq != 60.01 {
+		t.Errorf("got state=%v freq=%v", status.State, status.Freq)
+	}
+	if status.Wiring != WiringSplitPhase || status.Voltage != 242 {
+		t.Errorf("got %v %v, want split-phase 242v", status.Wiring, status.Voltage)
+	}
+
+	// a device without any battery energy is an error
+	r.Components.BMS = r.Components.BMS[:0]
+	if _, err := simpleDeviceStatus(&r); err == nil {
+		t.Errorf("expected error without BMS")
+	}
+}
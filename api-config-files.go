@@ -0,0 +1,71 @@
+package powerwall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrConfigNotFound is wrapped by [ConfigFileError] when the gateway doesn't have a requested file.
+	// This is assumed from the shape of the reply (a code, but no file): it hasn't been checked against a real
+	// gateway's reply for a missing file.
+	ErrConfigNotFound = errors.New("config file not found")
+
+	// KnownConfigFiles is the default list probed by [ProbeConfigFiles].
+	// Only "config.json" is known to exist everywhere; Tesla doesn't publish a list, so the rest are likely names that
+	// may not exist on any given firmware.
+	KnownConfigFiles = []string{
+		"config.json",
+		"site_info.json",
+		"grid_code.json",
+		"meters.json",
+		"tariff.json",
+		"island_config.json",
+		"backup_config.json",
+		"operation.json",
+	}
+)
+
+// ConfigFileError is returned by [TEDApi.Config] when a specific file can't be read.
+type ConfigFileError struct {
+	File string
+	Err  error
+}
+
+func (e *ConfigFileError) Error() string {
+	return fmt.Sprintf("config %q: %v", e.File, e.Err)
+}
+
+func (e *ConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigFile is the result of probing for a single config file.
+type ConfigFile struct {
+	Name    string `json:"name"`
+	Exists  bool   `json:"exists"`
+	Size    int    `json:"size"`
+	Content []byte `json:"content,omitzero"`
+}
+
+// ProbeConfigFiles tries to read each named config file from the gateway (or [KnownConfigFiles] if none are given).
+// Files that don't exist are returned with Exists false; any other failure stops the probe.
+func ProbeConfigFiles(ctx context.Context, td *TEDApi, names ...string) (out []ConfigFile, err error) {
+	if len(names) == 0 {
+		names = KnownConfigFiles
+	}
+
+	for _, name := range names {
+		content, err := td.Config(ctx, name)
+		if errors.Is(err, ErrConfigNotFound) {
+			out = append(out, ConfigFile{Name: name})
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		out = append(out, ConfigFile{Name: name, Exists: true, Size: len(content), Content: content})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package powerwall
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+func TestDecodeConfigReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		recv    *protocol.PayloadConfigRecv
+		want    string
+		wantErr error
+	}{
+		{"missing", nil, "", ErrProtocol},
+		{"empty", &protocol.PayloadConfigRecv{}, "", ErrProtocol},
+		{"not found", &protocol.PayloadConfigRecv{Code: []byte{1}}, "", ErrConfigNotFound},
+		{"file", &protocol.PayloadConfigRecv{File: &protocol.ConfigString{Name: "config.json", Text: `{"vin":"x"}`}}, `{"vin":"x"}`, nil},
+		{"empty file", &protocol.PayloadConfigRecv{File: &protocol.ConfigString{Name: "config.json"}}, "", nil},
+	}
+	for _, tt := range tests {
+		got, err := decodeConfigReply(tt.recv)
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+			t.Errorf("%s: got err %v, want %v", tt.name, err, tt.wantErr)
+		} else if string(got) != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestProbeConfigFiles(t *testing.T) {
+	var requested []string
+	s := newMessageGateway(t, func(req *protocol.MessageEnvelope) *protocol.MessageEnvelope {
+		name := req.GetConfig().GetSend().GetFile()
+		requested = append(requested, name)
+
+		recv := &protocol.PayloadConfigRecv{Code: []byte{1}}
+		if name == "config.json" {
+			recv = &protocol.PayloadConfigRecv{File: &protocol.ConfigString{Name: name, Text: `{"vin":"x"}`}}
+		}
+		return &protocol.MessageEnvelope{Config: &protocol.ConfigType{Config: &protocol.ConfigType_Recv{Recv: recv}}}
+	})
+	td := &TEDApi{BaseURL: s.URL, Secret: "secret"}
+
+	tests := []struct {
+		name  string
+		names []string
+		want  []ConfigFile
+	}{
+		{"default", nil, nil}, // checked below
+		{"named", []string{"config.json", "missing.json"}, []ConfigFile{
+			{Name: "config.json", Exists: true, Size: 11, Content: []byte(`{"vin":"x"}`)},
+			{Name: "missing.json"},
+		}},
+	}
+	for _, tt := range tests {
+		requested = nil
+		got, err := ProbeConfigFiles(context.Background(), td, tt.names...)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+
+		if tt.names == nil {
+			if !slices.Equal(requested, KnownConfigFiles) {
+				t.Errorf("%s: probed %q, want %q", tt.name, requested, KnownConfigFiles)
+			}
+			if len(got) != len(KnownConfigFiles) || !got[0].Exists || got[1].Exists {
+				t.Errorf("%s: got %+v", tt.name, got)
+			}
+			continue
+		}
+
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %+v", tt.name, got)
+		}
+		for i := range got {
+			if got[i].Name != tt.want[i].Name || got[i].Exists != tt.want[i].Exists || got[i].Size != tt.want[i].Size || string(got[i].Content) != string(tt.want[i].Content) {
+				t.Errorf("%s: got %+v, want %+v", tt.name, got[i], tt.want[i])
+			}
+		}
+	}
+}
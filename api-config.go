@@ -0,0 +1,255 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// SiteConfig is the typed form of the gateway's "config.json".
+// Only the commonly useful parts are typed; everything else is kept in Extra so it round-trips through JSON.
+// Typed fields that were present but zero (e.g., a reserve of 0) are also kept in Extra, so they're not dropped.
+type SiteConfig struct {
+	VIN             string               `json:"vin,omitzero"`
+	SiteInfo        ConfigSiteInfo       `json:"site_info"`
+	DefaultRealMode string               `json:"default_real_mode,omitzero"` // operation mode, e.g., "self_consumption", "autonomous", "backup"
+	Meters          []ConfigMeter        `json:"meters,omitzero"`
+	BatteryBlocks   []ConfigBatteryBlock `json:"battery_blocks,omitzero"`
+	Solars          []ConfigSolar        `json:"solars,omitzero"`
+	Tariff          *ConfigTariff        `json:"tariff_content,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ConfigSiteInfo describes the site, its location and its nameplate capacity.
+type ConfigSiteInfo struct {
+	SiteName             string         `json:"site_name,omitzero"`
+	Timezone             string         `json:"timezone,omitzero"`
+	Latitude             float64        `json:"latitude,omitzero"`
+	Longitude            float64        `json:"longitude,omitzero"`
+	BackupReservePercent float64        `json:"backup_reserve_percent,omitzero"`
+	NominalEnergyAC      float64        `json:"nominal_system_energy_ac,omitzero"` // kWh
+	NominalPowerAC       float64        `json:"nominal_system_power_ac,omitzero"`  // kW
+	GridCode             ConfigGridCode `json:"grid_code"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ConfigGridCode is the grid profile the site is configured for.
+type ConfigGridCode struct {
+	GridCode     string  `json:"grid_code,omitzero"`
+	Voltage      float64 `json:"grid_voltage_setting,omitzero"`
+	Freq         float64 `json:"grid_freq_setting,omitzero"`
+	PhaseSetting string  `json:"grid_phase_setting,omitzero"` // e.g., "Split", "Single", "Three"
+	Country      string  `json:"country,omitzero"`
+	State        string  `json:"state,omitzero"`
+	Utility      string  `json:"utility,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ConfigMeter is a configured meter, e.g., a Neurio or remote meter.
+type ConfigMeter struct {
+	Location string `json:"location,omitzero"` // e.g., "site", "solar"
+	Type     string `json:"type,omitzero"`
+	Serial   string `json:"serial,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ConfigBatteryBlock is a configured Powerwall.
+type ConfigBatteryBlock struct {
+	VIN  string `json:"vin,omitzero"` // this is the DIN
+	Type string `json:"type,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ConfigSolar is a configured solar array or inverter.
+type ConfigSolar struct {
+	Brand            string  `json:"brand,omitzero"`
+	Model            string  `json:"model,omitzero"`
+	PowerRatingWatts float64 `json:"power_rating_watts,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// ConfigTariff is the configured utility tariff.
+// Its charges are left as raw JSON as their shape varies between utilities.
+type ConfigTariff struct {
+	Code          string          `json:"code,omitzero"`
+	Name          string          `json:"name,omitzero"`
+	Utility       string          `json:"utility,omitzero"`
+	DailyCharges  json.RawMessage `json:"daily_charges,omitzero"`
+	EnergyCharges json.RawMessage `json:"energy_charges,omitzero"`
+	Seasons       json.RawMessage `json:"seasons,omitzero"`
+	SellTariff    json.RawMessage `json:"sell_tariff,omitzero"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// SolarCapacityW returns the total nameplate rating of all configured solar.
+func (c *SiteConfig) SolarCapacityW() (out float64) {
+	for _, s := range c.Solars {
+		out += s.PowerRatingWatts
+	}
+	return out
+}
+
+// GetSiteConfig reads and decodes "config.json" from your Powerwall system.
+func GetSiteConfig(ctx context.Context, td *TEDApi) (config *SiteConfig, err error) {
+	out, err := td.Config(ctx, "config.json")
+	if err != nil {
+		return nil, err
+	}
+
+	config = &SiteConfig{}
+	err = json.Unmarshal(out, config)
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func (c *SiteConfig) UnmarshalJSON(b []byte) error {
+	type plain SiteConfig
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c SiteConfig) MarshalJSON() ([]byte, error) {
+	type plain SiteConfig
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (c *ConfigSiteInfo) UnmarshalJSON(b []byte) error {
+	type plain ConfigSiteInfo
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c ConfigSiteInfo) MarshalJSON() ([]byte, error) {
+	type plain ConfigSiteInfo
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (c *ConfigGridCode) UnmarshalJSON(b []byte) error {
+	type plain ConfigGridCode
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c ConfigGridCode) MarshalJSON() ([]byte, error) {
+	type plain ConfigGridCode
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (c *ConfigMeter) UnmarshalJSON(b []byte) error {
+	type plain ConfigMeter
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c ConfigMeter) MarshalJSON() ([]byte, error) {
+	type plain ConfigMeter
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (c *ConfigBatteryBlock) UnmarshalJSON(b []byte) error {
+	type plain ConfigBatteryBlock
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c ConfigBatteryBlock) MarshalJSON() ([]byte, error) {
+	type plain ConfigBatteryBlock
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (c *ConfigSolar) UnmarshalJSON(b []byte) error {
+	type plain ConfigSolar
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c ConfigSolar) MarshalJSON() ([]byte, error) {
+	type plain ConfigSolar
+	return marshalExtra(plain(c), c.Extra)
+}
+
+func (c *ConfigTariff) UnmarshalJSON(b []byte) error {
+	type plain ConfigTariff
+	return unmarshalExtra(b, (*plain)(c), &c.Extra)
+}
+
+func (c ConfigTariff) MarshalJSON() ([]byte, error) {
+	type plain ConfigTariff
+	return marshalExtra(plain(c), c.Extra)
+}
+
+// unmarshalExtra decodes into v (a pointer to struct) and puts any fields v doesn't know about into extra.
+// Known fields that were present but decoded to zero are put into extra too, as "omitzero" would otherwise drop them.
+func unmarshalExtra(b []byte, v any, extra *map[string]json.RawMessage) error {
+	err := json.Unmarshal(b, v)
+	if err != nil {
+		return err
+	}
+
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(b, &all)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v).Elem()
+	for name, index := range jsonFieldNames(rv.Type()) {
+		if rv.Field(index).IsZero() {
+			continue
+		}
+		// encoding/json matches keys case-insensitively, so any of these may have been decoded into the field
+		for key := range all {
+			if strings.EqualFold(key, name) {
+				delete(all, key)
+			}
+		}
+	}
+
+	*extra = nil
+	if len(all) != 0 {
+		*extra = all
+	}
+	return nil
+}
+
+// marshalExtra encodes v (a struct) and merges in extra; the typed fields win, matching keys case-insensitively.
+func marshalExtra(v any, extra map[string]json.RawMessage) ([]byte, error) {
+	b, err := json.Marshal(v)
+	if err != nil || len(extra) == 0 {
+		return b, err
+	}
+
+	var all map[string]json.RawMessage
+	err = json.Unmarshal(b, &all)
+	if err != nil {
+		return nil, err
+	}
+	typed := slices.Collect(maps.Keys(all))
+	for k, v := range extra {
+		if !slices.ContainsFunc(typed, func(name string) bool { return strings.EqualFold(name, k) }) {
+			all[k] = v
+		}
+	}
+	return json.Marshal(all)
+}
+
+// jsonFieldNames returns the JSON names used by the fields of struct type t, and their field index.
+func jsonFieldNames(t reflect.Type) (out map[string]int) {
+	out = map[string]int{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		} else if name == "" {
+			name = f.Name
+		}
+		out[name] = i
+	}
+	return out
+}
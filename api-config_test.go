@@ -0,0 +1,71 @@
+package powerwall
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSiteConfigRoundTrip(t *testing.T) {
+	raw, err := os.ReadFile("testdata/config.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var config SiteConfig
+	if err := json.Unmarshal(raw, &config); err != nil {
+		t.Fatal(err)
+	}
+	if config.SiteInfo.GridCode.PhaseSetting != "Split" || len(config.BatteryBlocks) != 1 || config.SolarCapacityW() != 7600 {
+		t.Errorf("bad typed fields: %+v", config)
+	}
+
+	out, err := json.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got any
+	json.Unmarshal(raw, &want)
+	json.Unmarshal(out, &got)
+	if !reflect.DeepEqual(want, got) {
+		t.Errorf("round-trip changed config.json:\nwant %v\n got %v", want, got)
+	}
+}
+
+func TestUnmarshalExtra(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"zero kept", `{"vin":"","type":"Pw3"}`, `{"type":"Pw3","vin":""}`},
+		{"unknown kept", `{"vin":"X","min_soe":0}`, `{"min_soe":0,"vin":"X"}`},
+		{"case-insensitive", `{"VIN":"X"}`, `{"vin":"X"}`},
+		{"case-insensitive zero", `{"VIN":""}`, `{"VIN":""}`},
+	}
+	for _, tt := range tests {
+		var b ConfigBatteryBlock
+		if err := json.Unmarshal([]byte(tt.in), &b); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		out, err := json.Marshal(b)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got := string(out); got != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	// a typed value set after decoding replaces a differently-cased key kept in Extra
+	var b ConfigBatteryBlock
+	json.Unmarshal([]byte(`{"VIN":""}`), &b)
+	b.VIN = "Y"
+	out, _ := json.Marshal(b)
+	if strings.Contains(string(out), "VIN") {
+		t.Errorf("duplicated key: %s", out)
+	}
+}
@@ -0,0 +1,166 @@
+package powerwall
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+)
+
+// FirmwareInventory lists the firmware running on every component found in a system.
+type FirmwareInventory struct {
+	Time       time.Time           `json:"time"` // local time this was fetched
+	Leader     string              `json:"dinLeader"`
+	Version    string              `json:"version"` // running system version, e.g., "25.10.1"
+	GitHash    string              `json:"gitHash"`
+	Components []FirmwareComponent `json:"components"`
+	Errors     map[string]string   `json:"errors,omitzero"` // by DIN, for devices that couldn't be queried
+}
+
+// FirmwareComponent is a single component and its firmware identifier (typically a git hash).
+type FirmwareComponent struct {
+	Type         string `json:"type"`           // e.g., "PCH", "BMS", "PVAC", "SYNC"
+	DIN          string `json:"din,omitzero"`   // device this was found on; the leader for esCan components
+	Index        int    `json:"index,omitzero"` // if there are many of the same type on one device
+	PartNumber   string `json:"partNumber,omitzero"`
+	SerialNumber string `json:"serialNumber,omitzero"`
+	Firmware     string `json:"firmware"`
+}
+
+// Key identifies this component across inventories.
+func (c FirmwareComponent) Key() string {
+	return fmt.Sprintf("%s/%s/%d", c.DIN, c.Type, c.Index)
+}
+
+// GetFirmwareInventory reads a [FirmwareInventory] from your Powerwall system.
+// This queries the leader, and then every battery block as an individual device.
+// If a battery block can't be queried, its error is recorded in Errors and the rest of the inventory is still returned.
+func GetFirmwareInventory(ctx context.Context, td *TEDApi) (inv *FirmwareInventory, err error) {
+	// this is the running version; updateUrgencyCheck in the status may instead be an available update
+	fw, err := td.Firmware(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := QueryInto[StatusResponse](ctx, td, QueryStatus, "")
+	if err != nil {
+		return nil, err
+	}
+
+	din := fw.DIN
+	inv = &FirmwareInventory{
+		Time:    time.Now(),
+		Leader:  din,
+		Version: fw.Version,
+		GitHash: fw.GitHash,
+	}
+	inv.Components = append(inv.Components, FirmwareComponent{
+		Type:         "Gateway",
+		DIN:          din,
+		PartNumber:   fw.PartNumber,
+		SerialNumber: fw.SerialNumber,
+		Firmware:     fw.GitHash,
+	})
+
+	// these all report "${TYPE}_InfoMsg.${TYPE}_appGitHash"
+	for _, typ := range []string{"PVAC", "THC", "POD", "MSA", "SYNC"} {
+		for i, dev := range response.EsCan.Bus[typ] {
+			inv.Components = append(inv.Components, FirmwareComponent{
+				Type:         typ,
+				DIN:          din,
+				Index:        i,
+				PartNumber:   dev.Text("packagePartNumber"),
+				SerialNumber: dev.Text("packageSerialNumber"),
+				Firmware:     dev.Message(typ + "_InfoMsg").Text(typ + "_appGitHash"),
+			})
+		}
+	}
+
+	for i, bb := range response.Control.BatteryBlocks {
+		// each block gets a fair share of what's left, so one dead follower can't use it all
+		blockCtx, cancel := Budget(ctx, len(response.Control.BatteryBlocks)-i)
+		r, err := queryComponents(blockCtx, td, bb.DIN)
+		cancel()
+		if err != nil {
+			if inv.Errors == nil {
+				inv.Errors = map[string]string{}
+			}
+			inv.Errors[bb.DIN] = err.Error()
+			continue
+		}
+
+		add := func(typ string, parts []Component) {
+			for i, part := range parts {
+				c := FirmwareComponent{
+					Type:         typ,
+					DIN:          bb.DIN,
+					Index:        i,
+					PartNumber:   part.PartNumber,
+					SerialNumber: part.SerialNumber,
+					Firmware:     part.Signals.Text(typ + "_appGitHash"),
+				}
+				if typ == "PCH" {
+					c.PartNumber, c.SerialNumber = pchPartSerial(part.Signals.ToMap())
+				}
+				inv.Components = append(inv.Components, c)
+			}
+		}
+		add("PCH", r.Components.PCH)
+		add("BMS", r.Components.BMS)
+		add("HVP", r.Components.HVP)
+		add("PWS", r.Components.PWS)
+	}
+
+	return inv, nil
+}
+
+// FirmwareHistory tracks firmware changes over successive calls to [FirmwareHistory.Observe].
+// It can be stored as JSON and restored between runs.
+type FirmwareHistory struct {
+	Current map[string]FirmwareComponent `json:"current"` // by [FirmwareComponent.Key]
+	Changes []FirmwareChange             `json:"changes"`
+}
+
+// FirmwareChange records a component appearing, disappearing or changing firmware.
+type FirmwareChange struct {
+	Time      time.Time         `json:"time"`
+	Component FirmwareComponent `json:"component"`
+	Previous  string            `json:"previous,omitzero"` // previous firmware, blank if newly seen
+	Removed   bool              `json:"removed,omitzero"`
+}
+
+// Observe records the given inventory, returning (and appending to Changes) anything that differs from the last one.
+// Nothing is reported as changed for the first inventory observed.
+// Components of devices listed in the inventory's Errors are kept as they were, rather than reported as removed.
+func (h *FirmwareHistory) Observe(inv *FirmwareInventory) (changes []FirmwareChange) {
+	first := h.Current == nil
+	next := make(map[string]FirmwareComponent, len(inv.Components))
+	for key, c := range h.Current {
+		if _, failed := inv.Errors[c.DIN]; failed {
+			next[key] = c
+		}
+	}
+
+	for _, c := range inv.Components {
+		key := c.Key()
+		next[key] = c
+
+		prev, ok := h.Current[key]
+		if first || (ok && prev.Firmware == c.Firmware) {
+			continue
+		}
+		changes = append(changes, FirmwareChange{Time: inv.Time, Component: c, Previous: prev.Firmware})
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(h.Current)) {
+		if _, ok := next[key]; !ok {
+			prev := h.Current[key]
+			changes = append(changes, FirmwareChange{Time: inv.Time, Component: prev, Previous: prev.Firmware, Removed: true})
+		}
+	}
+
+	h.Current = next
+	h.Changes = append(h.Changes, changes...)
+	return changes
+}
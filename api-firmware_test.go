@@ -0,0 +1,49 @@
+package powerwall
+
+import (
+	"testing"
+)
+
+func TestFirmwareHistoryObserve(t *testing.T) {
+	pch := FirmwareComponent{Type: "PCH", DIN: "F1", Firmware: "aaa"}
+	bms := FirmwareComponent{Type: "BMS", DIN: "F1", Firmware: "bbb"}
+	gw := FirmwareComponent{Type: "Gateway", DIN: "L", Firmware: "ggg"}
+
+	updated := pch
+	updated.Firmware = "ccc"
+
+	tests := []struct {
+		name string
+		inv  FirmwareInventory
+		want []FirmwareChange
+	}{
+		{"first", FirmwareInventory{Components: []FirmwareComponent{gw, pch, bms}}, nil},
+		{"same", FirmwareInventory{Components: []FirmwareComponent{gw, pch, bms}}, nil},
+		{"updated", FirmwareInventory{Components: []FirmwareComponent{gw, updated, bms}}, []FirmwareChange{
+			{Component: updated, Previous: "aaa"},
+		}},
+		{"device failed", FirmwareInventory{Components: []FirmwareComponent{gw}, Errors: map[string]string{"F1": "timeout"}}, nil},
+		{"device back", FirmwareInventory{Components: []FirmwareComponent{gw, updated, bms}}, nil},
+		{"removed", FirmwareInventory{Components: []FirmwareComponent{gw, updated}}, []FirmwareChange{
+			{Component: bms, Previous: "bbb", Removed: true},
+		}},
+	}
+
+	var h FirmwareHistory
+	var all int
+	for _, tt := range tests {
+		got := h.Observe(&tt.inv)
+		all += len(tt.want)
+		if len(got) != len(tt.want) {
+			t.Fatalf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: got %+v, want %+v", tt.name, got[i], tt.want[i])
+			}
+		}
+	}
+	if len(h.Changes) != all {
+		t.Errorf("got %d changes, want %d", len(h.Changes), all)
+	}
+}
@@ -0,0 +1,167 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Measurement is a single value that might not have been reported.
+// It encodes to JSON as a number, or null if it's not Valid.
+type Measurement struct {
+	Value float64
+	Valid bool // whether Value was actually reported
+	MIA   bool // the component that reports this was missing-in-action or incomplete; Value may be stale
+}
+
+func (m Measurement) MarshalJSON() ([]byte, error) {
+	if !m.Valid {
+		return []byte("null"), nil
+	}
+	return json.Marshal(m.Value)
+}
+
+func (m *Measurement) UnmarshalJSON(b []byte) error {
+	var v *float64
+	err := json.Unmarshal(b, &v)
+	if err != nil {
+		return err
+	}
+	*m = Measurement{}
+	if v != nil {
+		m.Value = *v
+		m.Valid = true
+	}
+	return nil
+}
+
+func (m Measurement) String() string {
+	switch {
+	case m.MIA:
+		return "MIA"
+	case !m.Valid:
+		return "-"
+	}
+	return fmt.Sprint(m.Value)
+}
+
+// NullableStatus is like [SimpleStatus], but each measurement tells zero apart from not reported.
+type NullableStatus struct {
+	SampleTime
+	Leader            string           `json:"dinLeader"`
+	Shutdown          bool             `json:"shutdown"`
+	Island            bool             `json:"island"`
+	BatteryEnergy     Measurement      `json:"battery"`
+	BatteryFullEnergy Measurement      `json:"batteryFull"`
+	PowerBattery      Measurement      `json:"powerBattery"`
+	PowerSite         Measurement      `json:"powerSite"`
+	PowerLoad         Measurement      `json:"powerLoad"`
+	PowerSolar        Measurement      `json:"powerSolar"`
+	PowerSolarRGM     Measurement      `json:"powerSolarRGM"`
+	PowerGenerator    Measurement      `json:"powerGenerator"`
+	PowerConductor    Measurement      `json:"powerConductor"`
+	BatteryBlocks     []string         `json:"batteryBlocks"`
+	Phase             [3]NullablePhase `json:"phase"`
+	MIA               []string         `json:"mia,omitzero"` // esCan messages reported as missing-in-action
+}
+
+type NullablePhase struct {
+	FreqLoad    Measurement `json:"freqLoad"`
+	FreqMain    Measurement `json:"freqMain"`
+	VoltageLoad Measurement `json:"voltageLoad"`
+	VoltageMain Measurement `json:"voltageMain"`
+}
+
+// meterSources lists the esCan messages that may measure each meter aggregate, as "TYPE.Message".
+// Tesla doesn't document this, so it's a best guess from the message names: the SYNC board's METER_X measures the site
+// and METER_Y measures solar, a PW3's MSA has a site meter as METER_Z, and a PVAC is a solar inverter.
+var meterSources = map[string][]string{
+	"SITE":  {"SYNC.METER_X_AcMeasurements", "MSA.METER_Z_AcMeasurements"},
+	"SOLAR": {"SYNC.METER_Y_AcMeasurements", "PVAC.PVAC_Status"},
+}
+
+// GetNullableStatus reads a [NullableStatus] struct from your Powerwall system.
+// Meter locations that aren't present, and values from esCan messages flagged isMIA (or not isComplete), aren't Valid.
+// A meter aggregate is also MIA if any device that measures it (see meterSources) is flagged isMIA, as the gateway
+// keeps reporting its last value.
+func GetNullableStatus(ctx context.Context, td *TEDApi) (status *NullableStatus, err error) {
+	sent := time.Now()
+	response, err := QueryInto[StatusResponse](ctx, td, QueryStatus, "")
+	if err != nil {
+		return nil, err
+	}
+	received := time.Now()
+
+	powerFor := func(s string) Measurement {
+		mia := response.EsCan.Bus.anyMIA(meterSources[s]...)
+		for _, m := range response.Control.MeterAggregates {
+			if m.Location != s {
+				continue
+			}
+			out := measurementOf(m.RealPowerW)
+			if mia {
+				out.Valid, out.MIA = false, true
+			}
+			return out
+		}
+		return Measurement{MIA: mia}
+	}
+
+	din, err := td.getDIN(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status = &NullableStatus{
+		Leader:            din,
+		Shutdown:          response.Control.SiteShutdown.IsShutdown,
+		Island:            !response.Control.Islanding.ContactorClosed,
+		BatteryEnergy:     measurementOf(response.Control.SystemStatus.NominalEnergyRemainingWh),
+		BatteryFullEnergy: measurementOf(response.Control.SystemStatus.NominalFullPackEnergyWh),
+		PowerBattery:      powerFor("BATTERY"),
+		PowerSite:         powerFor("SITE"),
+		PowerLoad:         powerFor("LOAD"),
+		PowerSolar:        powerFor("SOLAR"),
+		PowerSolarRGM:     powerFor("SOLAR_RGM"),
+		PowerGenerator:    powerFor("GENERATOR"),
+		PowerConductor:    powerFor("CONDUCTOR"),
+		MIA:               response.EsCan.Bus.mia(),
+	}
+	for _, bb := range response.Control.BatteryBlocks {
+		status.BatteryBlocks = append(status.BatteryBlocks, bb.DIN)
+	}
+
+	status.SampleTime = SampleTime{
+		GatewayTime:  parseGatewayTime(response.System.Time),
+		SentTime:     sent,
+		ReceivedTime: received,
+	}
+	if !status.GatewayTime.IsZero() {
+		status.BusAge = response.EsCan.Bus.oldestRx(status.GatewayTime)
+	}
+
+	var ac EsCanMessage
+	if islander := response.EsCan.Bus["ISLANDER"]; len(islander) != 0 {
+		ac = islander[0].Message("ISLAND_AcMeasurements")
+	}
+	for i := range 3 {
+		phase := i + 1
+
+		status.Phase[i] = NullablePhase{
+			FreqLoad:    ac.measurement(fmt.Sprintf("ISLAND_FreqL%d_Load", phase)),
+			FreqMain:    ac.measurement(fmt.Sprintf("ISLAND_FreqL%d_Main", phase)),
+			VoltageLoad: ac.measurement(fmt.Sprintf("ISLAND_VL%dN_Load", phase)),
+			VoltageMain: ac.measurement(fmt.Sprintf("ISLAND_VL%dN_Main", phase)),
+		}
+	}
+
+	return status, nil
+}
+
+func measurementOf(v *float64) Measurement {
+	if v == nil {
+		return Measurement{}
+	}
+	return Measurement{Value: *v, Valid: true}
+}
@@ -0,0 +1,137 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+func TestMeasurementJSON(t *testing.T) {
+	tests := []struct {
+		m    Measurement
+		want string
+	}{
+		{Measurement{}, "null"},
+		{Measurement{Value: 0, Valid: true}, "0"},
+		{Measurement{Value: -1250.5, Valid: true}, "-1250.5"},
+		{Measurement{Value: 3100, MIA: true}, "null"},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.m)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(b) != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.m, b, tt.want)
+		}
+
+		// MIA isn't encoded, so only Value and Valid survive
+		var back Measurement
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Fatal(err)
+		}
+		want := Measurement{Value: tt.m.Value, Valid: tt.m.Valid}
+		if !tt.m.Valid {
+			want = Measurement{}
+		}
+		if back != want {
+			t.Errorf("%s: decoded %+v, want %+v", b, back, want)
+		}
+	}
+
+	var m Measurement
+	if err := json.Unmarshal([]byte(`"12"`), &m); err == nil {
+		t.Errorf("decoded string as %+v", m)
+	}
+}
+
+func TestEsCanMessageMeasurement(t *testing.T) {
+	tests := []struct {
+		name string
+		m    EsCanMessage
+		want Measurement
+	}{
+		{"absent message", nil, Measurement{}},
+		{"valid", EsCanMessage{"isMIA": false, "v": 120.5}, Measurement{Value: 120.5, Valid: true}},
+		{"zero", EsCanMessage{"v": 0.0}, Measurement{Value: 0, Valid: true}},
+		{"missing key", EsCanMessage{"isMIA": false}, Measurement{}},
+		{"not a number", EsCanMessage{"v": "120"}, Measurement{}},
+		{"MIA", EsCanMessage{"isMIA": true, "v": 120.5}, Measurement{Value: 120.5, MIA: true}},
+		{"incomplete", EsCanMessage{"isComplete": false, "v": 120.5}, Measurement{Value: 120.5, MIA: true}},
+	}
+	for _, tt := range tests {
+		if got := tt.m.measurement("v"); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGetNullableStatus(t *testing.T) {
+	fixture, err := os.ReadFile("testdata/status-nullable.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := newMessageGateway(t, func(req *protocol.MessageEnvelope) *protocol.MessageEnvelope {
+		return &protocol.MessageEnvelope{Payload: &protocol.QueryType{Recv: &protocol.PayloadString{Text: string(fixture)}}}
+	})
+	td := &TEDApi{BaseURL: s.URL, Secret: "secret"}
+
+	status, err := GetNullableStatus(context.Background(), td)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status.Leader != fakeLeaderDIN || status.Island || status.Shutdown {
+		t.Errorf("got leader=%q island=%v shutdown=%v", status.Leader, status.Island, status.Shutdown)
+	}
+	if !slices.Equal(status.BatteryBlocks, []string{fakeLeaderDIN}) {
+		t.Errorf("got battery blocks %q", status.BatteryBlocks)
+	}
+
+	power := []struct {
+		name string
+		got  Measurement
+		want Measurement
+	}{
+		{"battery energy", status.BatteryEnergy, Measurement{Value: 7750, Valid: true}},
+		{"site", status.PowerSite, Measurement{Value: 0, Valid: true}},
+		{"battery", status.PowerBattery, Measurement{Value: -1250.5, Valid: true}},
+		{"load (null)", status.PowerLoad, Measurement{}},
+		{"solar (CT is MIA)", status.PowerSolar, Measurement{Value: 3100, MIA: true}},
+		{"generator (absent)", status.PowerGenerator, Measurement{}},
+	}
+	for _, tt := range power {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if !slices.Equal(status.MIA, []string{"SYNC.METER_Y_AcMeasurements"}) {
+		t.Errorf("got MIA %q", status.MIA)
+	}
+	if status.BusAge != 10*time.Minute {
+		t.Errorf("got bus age %v", status.BusAge)
+	}
+
+	phase := status.Phase
+	if phase[0].VoltageMain != (Measurement{Value: 121.5, Valid: true}) || phase[0].VoltageLoad != (Measurement{Value: 0, Valid: true}) {
+		t.Errorf("got phase 1 %+v", phase[0])
+	}
+	if phase[2].VoltageMain.Valid || phase[2].FreqLoad.Valid {
+		t.Errorf("got unreported phase 3 %+v", phase[2])
+	}
+
+	b, err := json.Marshal(status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]any
+	json.Unmarshal(b, &decoded)
+	if decoded["powerSolar"] != nil || decoded["powerSite"] != 0.0 {
+		t.Errorf("got JSON solar=%v site=%v", decoded["powerSolar"], decoded["powerSite"])
+	}
+}
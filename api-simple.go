@@ -1,12 +1,9 @@
 package powerwall
 
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-)
+import "context"
 
 type SimpleStatus struct {
+	SampleTime
 	Leader            string         `json:"dinLeader"`
 	Shutdown          bool           `json:"shutdown"`
 	Island            bool           `json:"island"`
@@ -21,6 +18,7 @@ type SimpleStatus struct {
 	PowerConductor    float64        `json:"powerConductor"`
 	BatteryBlocks     []string       `json:"batteryBlocks"`
 	Phase             [3]SimplePhase `json:"phase"`
+	Wiring            Wiring         `json:"wiring"` // detected from which phases are live, and their voltage
 
 	// Devices []SimpleStatusDevice `json:"devices"`
 }
@@ -39,115 +37,38 @@ type SimplePhase struct {
 
 // GetSimpleStatus reads a [SimpleStatus] struct from your Powerwall system.
 // This contains aggregate information from the leader.
+// Values that weren't reported are zero; use [GetNullableStatus] to tell these apart.
 func GetSimpleStatus(ctx context.Context, td *TEDApi) (status *SimpleStatus, err error) {
-	out, err := td.Query(ctx, QueryStatus)
-	if err != nil {
-		return nil, err
-	}
-
-	type statusResponse struct {
-		Control struct {
-			Alerts struct {
-				Active []string `json:"active"`
-			} `json:"alerts"`
-			BatteryBlocks []struct {
-				DIN            string   `json:"din"`
-				DisableReasons []string `json:"disableReasons"`
-			} `json:"batteryBlocks"`
-			Islanding struct {
-				ContactorClosed    bool     `json:"contactorClosed"`
-				CustomerIslandMode string   `json:"customerIslandMode"`
-				DisableReasons     []string `json:"disableReasons"`
-				GridOK             bool     `json:"gridOK"`
-				MicroGridOK        bool     `json:"microGridOK"`
-			} `json:"islanding"`
-			MeterAggregates []struct {
-				Location   string  `json:"location"`
-				RealPowerW float64 `json:"realPowerW"`
-			} `json:"meterAggregates"`
-			PVInverters []struct {
-				// TODO: not sure what goes here - maybe for PW2?
-			} `json:"pvInverters"`
-			SiteShutdown struct {
-				IsShutdown bool     `json:"isShutDown"`
-				Reasons    []string `json:"reasons"`
-			} `json:"siteShutdown"`
-			SystemStatus struct {
-				// these usually show up as int but rarely have e.g., .0000000004
-				NominalEnergyRemainingWh float64 `json:"nominalEnergyRemainingWh"`
-				NominalFullPackEnergyWh  float64 `json:"nominalFullPackEnergyWh"`
-			} `json:"systemStatus"`
-		} `json:"control"`
-
-		EsCan struct {
-			Bus struct {
-				Islander struct {
-					AcMeasurements map[string]any `json:"ISLAND_AcMeasurements"`
-				} `json:"ISLANDER"`
-			} `json:"bus"`
-		} `json:"esCan"`
-	}
-	var response statusResponse
-
-	err = json.Unmarshal(out, &response)
-	if err != nil {
-		return nil, err
-	}
-
-	// var raw map[string]any
-	// json.Unmarshal(out, &raw)
-	// nice, _ := json.MarshalIndent(raw, "", "  ")
-	// fmt.Printf("%s\n", string(nice))
-
-	powerFor := func(s string) float64 {
-		for _, m := range response.Control.MeterAggregates {
-			if m.Location == s {
-				return m.RealPowerW
-			}
-		}
-		return 0.0
-	}
-
-	din, err := td.getDIN(ctx)
+	n, err := GetNullableStatus(ctx, td)
 	if err != nil {
 		return nil, err
 	}
 
 	status = &SimpleStatus{
-		Leader:            din,
-		Shutdown:          response.Control.SiteShutdown.IsShutdown,
-		Island:            !response.Control.Islanding.ContactorClosed,
-		BatteryEnergy:     int(response.Control.SystemStatus.NominalEnergyRemainingWh),
-		BatteryFullEnergy: int(response.Control.SystemStatus.NominalFullPackEnergyWh),
-		PowerBattery:      powerFor("BATTERY"),
-		PowerSite:         powerFor("SITE"),
-		PowerLoad:         powerFor("LOAD"),
-		PowerSolar:        powerFor("SOLAR"),
-		PowerSolarRGM:     powerFor("SOLAR_RGM"),
-		PowerGenerator:    powerFor("GENERATOR"),
-		PowerConductor:    powerFor("CONDUCTOR"),
-	}
-	for _, bb := range response.Control.BatteryBlocks {
-		status.BatteryBlocks = append(status.BatteryBlocks, bb.DIN)
+		SampleTime:        n.SampleTime,
+		Leader:            n.Leader,
+		Shutdown:          n.Shutdown,
+		Island:            n.Island,
+		BatteryEnergy:     int(n.BatteryEnergy.Value),
+		BatteryFullEnergy: int(n.BatteryFullEnergy.Value),
+		PowerBattery:      n.PowerBattery.Value,
+		PowerSite:         n.PowerSite.Value,
+		PowerLoad:         n.PowerLoad.Value,
+		PowerSolar:        n.PowerSolar.Value,
+		PowerSolarRGM:     n.PowerSolarRGM.Value,
+		PowerGenerator:    n.PowerGenerator.Value,
+		PowerConductor:    n.PowerConductor.Value,
+		BatteryBlocks:     n.BatteryBlocks,
 	}
-
-	for i := range 3 {
-		phase := i + 1
-
-		ac := response.EsCan.Bus.Islander.AcMeasurements
-		floatFor := func(s string) (out float64) {
-			out, _ = ac[s].(float64)
-			return
-		}
-
+	for i, phase := range n.Phase {
 		status.Phase[i] = SimplePhase{
-			FreqLoad:    floatFor(fmt.Sprintf("ISLAND_FreqL%d_Load", phase)),
-			FreqMain:    floatFor(fmt.Sprintf("ISLAND_FreqL%d_Main", phase)),
-			VoltageLoad: floatFor(fmt.Sprintf("ISLAND_VL%dN_Load", phase)),
-			VoltageMain: floatFor(fmt.Sprintf("ISLAND_VL%dN_Main", phase)),
+			FreqLoad:    phase.FreqLoad.Value,
+			FreqMain:    phase.FreqMain.Value,
+			VoltageLoad: phase.VoltageLoad.Value,
+			VoltageMain: phase.VoltageMain.Value,
 		}
-
 	}
+	status.Wiring = detectSiteWiring(status.Phase)
 
 	return status, nil
 }
@@ -0,0 +1,205 @@
+package powerwall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Topology describes the devices that make up a Powerwall installation.
+type Topology struct {
+	Root *TopologyNode `json:"root"` // always the gateway
+
+	ExpectedPowerwalls  int  `json:"numACPW"` // from the gateway's enumeration
+	ExpectedPVInverters int  `json:"numPVI"`
+	Enumerating         bool `json:"enumerating,omitzero"` // enumeration still running, may be incomplete
+
+	Warnings []string `json:"warnings,omitzero"` // parts of discovery that failed
+}
+
+// TopologyNode is a single device within a [Topology].
+type TopologyNode struct {
+	Type         string          `json:"type"`          // e.g., "Gateway", "Powerwall", "PVInverter", "Neurio", "RemoteMeter", "MSA", "SYNC", "ISLANDER"
+	Role         string          `json:"role,omitzero"` // "leader" or "follower" for Powerwalls
+	DIN          string          `json:"din,omitzero"`
+	PartNumber   string          `json:"partNumber,omitzero"`
+	SerialNumber string          `json:"serialNumber,omitzero"`
+	Firmware     string          `json:"firmware,omitzero"`
+	Children     []*TopologyNode `json:"children,omitzero"`
+}
+
+// DiscoverTopology builds a [Topology] of your Powerwall system.
+// This makes a few requests to the leader, but doesn't talk to followers directly.
+func DiscoverTopology(ctx context.Context, td *TEDApi) (t *Topology, err error) {
+	fw, err := td.Firmware(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leaderDIN, err := td.LeaderDIN(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := QueryInto[StatusResponse](ctx, td, QueryStatus, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// remote meters are only in the larger query; don't fail if it's not supported
+	meters, metersErr := discoverRemoteMeters(ctx, td)
+
+	t = buildTopology(fw, leaderDIN, response, meters)
+	if metersErr != nil {
+		t.Warnings = append(t.Warnings, fmt.Sprintf("could not read remote meters: %v", metersErr))
+	}
+	return t, nil
+}
+
+// escanInfoPrefix is the message prefix of each type of esCan device shown in a [Topology].
+// ISLANDER's messages are prefixed "ISLAND_" (e.g., "ISLAND_GridConnection"). None of the known signed queries ask
+// for its info message, so its firmware is usually blank.
+var escanInfoPrefix = map[string]string{
+	"MSA":      "MSA",
+	"SYNC":     "SYNC",
+	"ISLANDER": "ISLAND",
+}
+
+// buildTopology assembles a [Topology] from the gateway's firmware message and status.
+// The leader is the battery block with leaderDIN, which is the DIN requests are addressed to.
+func buildTopology(fw *GatewayFirmware, leaderDIN string, response *StatusResponse, meters []*TopologyNode) (t *Topology) {
+	root := &TopologyNode{
+		Type:         "Gateway",
+		DIN:          fw.DIN,
+		PartNumber:   fw.PartNumber,
+		SerialNumber: fw.SerialNumber,
+		Firmware:     fw.Version,
+	}
+	t = &Topology{
+		Root:                root,
+		ExpectedPowerwalls:  response.EsCan.Enumeration.NumACPW,
+		ExpectedPVInverters: response.EsCan.Enumeration.NumPVI,
+		Enumerating:         response.EsCan.Enumeration.InProgress,
+	}
+
+	// the leader is the Powerwall hosting the gateway (PW3); a PW2 system may have no leader
+	for _, bb := range response.Control.BatteryBlocks {
+		node := &TopologyNode{Type: "Powerwall", Role: "follower", DIN: bb.DIN}
+		node.PartNumber, node.SerialNumber = splitDIN(bb.DIN)
+		if bb.DIN == leaderDIN {
+			node.Role = "leader"
+			root.Children = append([]*TopologyNode{node}, root.Children...)
+		} else {
+			root.Children = append(root.Children, node)
+		}
+	}
+
+	for _, pvi := range response.Control.PVInverters {
+		node := &TopologyNode{Type: "PVInverter", DIN: pvi.DIN}
+		node.PartNumber, node.SerialNumber = splitDIN(pvi.DIN)
+		root.Children = append(root.Children, node)
+	}
+
+	for _, p := range response.Neurio.Pairings {
+		root.Children = append(root.Children, &TopologyNode{Type: "Neurio", SerialNumber: p.Serial})
+	}
+
+	root.Children = append(root.Children, meters...)
+
+	for _, typ := range []string{"MSA", "SYNC", "ISLANDER"} {
+		prefix := escanInfoPrefix[typ]
+		for _, dev := range response.EsCan.Bus[typ] {
+			root.Children = append(root.Children, &TopologyNode{
+				Type:         typ,
+				PartNumber:   dev.Text("packagePartNumber"),
+				SerialNumber: dev.Text("packageSerialNumber"),
+				Firmware:     dev.Message(prefix + "_InfoMsg").Text(prefix + "_appGitHash"),
+			})
+		}
+	}
+
+	if n := len(response.Control.BatteryBlocks); t.ExpectedPowerwalls != 0 && n != t.ExpectedPowerwalls {
+		t.Warnings = append(t.Warnings, fmt.Sprintf("expected %d Powerwalls, found %d", t.ExpectedPowerwalls, n))
+	}
+
+	return t
+}
+
+func discoverRemoteMeters(ctx context.Context, td *TEDApi) (nodes []*TopologyNode, err error) {
+	response, err := QueryInto[DeviceControllerResponse](ctx, td, QueryDeviceController, "")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, m := range response.TeslaRemoteMeter.Meters {
+		node := &TopologyNode{Type: "RemoteMeter", DIN: m.DIN, Firmware: m.Reading.FirmwareVersion}
+		node.PartNumber, node.SerialNumber = splitDIN(m.DIN)
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+// splitDIN splits a DIN into its part and serial number, e.g., "1707000-11-J--TG12345" => "1707000-11-J", "TG12345".
+func splitDIN(din string) (part, serial string) {
+	part, serial, _ = strings.Cut(din, "--")
+	return part, serial
+}
+
+// String renders the topology as a text tree, suitable for pasting into a support ticket.
+func (t *Topology) String() string {
+	var sb strings.Builder
+
+	var render func(n *TopologyNode, prefix string, last, root bool)
+	render = func(n *TopologyNode, prefix string, last, root bool) {
+		childPrefix := prefix
+		if !root {
+			if last {
+				sb.WriteString(prefix + "└── ")
+				childPrefix += "    "
+			} else {
+				sb.WriteString(prefix + "├── ")
+				childPrefix += "│   "
+			}
+		}
+		sb.WriteString(n.String())
+		sb.WriteString("\n")
+
+		for i, c := range n.Children {
+			render(c, childPrefix, i == len(n.Children)-1, false)
+		}
+	}
+	if t.Root != nil {
+		render(t.Root, "", true, true)
+	}
+
+	fmt.Fprintf(&sb, "expected Powerwalls=%d PVInverters=%d", t.ExpectedPowerwalls, t.ExpectedPVInverters)
+	if t.Enumerating {
+		sb.WriteString(" (enumerating)")
+	}
+	sb.WriteString("\n")
+	for _, w := range t.Warnings {
+		fmt.Fprintf(&sb, "warning: %s\n", w)
+	}
+	return sb.String()
+}
+
+// String describes this node on a single line (without its children).
+func (n *TopologyNode) String() string {
+	parts := []string{n.Type}
+	if n.Role != "" {
+		parts = append(parts, "("+n.Role+")")
+	}
+	if n.DIN != "" {
+		parts = append(parts, n.DIN)
+	}
+	if n.PartNumber != "" {
+		parts = append(parts, "part="+n.PartNumber)
+	}
+	if n.SerialNumber != "" {
+		parts = append(parts, "serial="+n.SerialNumber)
+	}
+	if n.Firmware != "" {
+		parts = append(parts, "fw="+n.Firmware)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,111 @@
+package powerwall
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+func TestSplitDIN(t *testing.T) {
+	tests := []struct {
+		din, part, serial string
+	}{
+		{"1707000-11-J--TG123456789012", "1707000-11-J", "TG123456789012"},
+		{"1707000-11-J", "1707000-11-J", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		part, serial := splitDIN(tt.din)
+		if part != tt.part || serial != tt.serial {
+			t.Errorf("%q: got %q, %q", tt.din, part, serial)
+		}
+	}
+}
+
+func TestBuildTopology(t *testing.T) {
+	raw, err := os.ReadFile("testdata/status-topology.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var response StatusResponse
+	if err := json.Unmarshal(raw, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	fw := &GatewayFirmware{DIN: "1152100-14-J--TG000000000000", PartNumber: "1152100-14-J", SerialNumber: "TG000000000000", Version: "25.10.1"}
+	meters := []*TopologyNode{{Type: "RemoteMeter", DIN: "1500000-00-A--RM1"}}
+	topo := buildTopology(fw, fakeLeaderDIN, &response, meters)
+
+	tests := []struct {
+		typ, role, part, serial, firmware string
+	}{
+		{"Powerwall", "leader", "1707000-11-J", "TG123456789012", ""},
+		{"Powerwall", "follower", "2012170-25-E", "TG000000000001", ""},
+		{"PVInverter", "", "1538000-45-C", "CN000000000003", ""},
+		{"Neurio", "", "", "OBB0000000000", ""},
+		{"RemoteMeter", "", "", "", ""},
+		{"MSA", "", "1624171-00-E", "TG000000000004", "a1b2c3"},
+		{"SYNC", "", "1493315-01-F", "TG000000000005", "d4e5f6"},
+		{"ISLANDER", "", "", "", "0a0b0c"},
+	}
+	children := topo.Root.Children
+	if len(children) != len(tests) {
+		t.Fatalf("got %d children, want %d", len(children), len(tests))
+	}
+	for i, tt := range tests {
+		c := children[i]
+		if c.Type != tt.typ || c.Role != tt.role || c.PartNumber != tt.part || c.SerialNumber != tt.serial || c.Firmware != tt.firmware {
+			t.Errorf("child %d: got %+v, want %+v", i, *c, tt)
+		}
+	}
+
+	if topo.ExpectedPowerwalls != 3 || topo.ExpectedPVInverters != 1 || !topo.Enumerating {
+		t.Errorf("got enumeration %d/%d/%v", topo.ExpectedPowerwalls, topo.ExpectedPVInverters, topo.Enumerating)
+	}
+	if len(topo.Warnings) != 1 || topo.Warnings[0] != "expected 3 Powerwalls, found 2" {
+		t.Errorf("got warnings %q", topo.Warnings)
+	}
+
+	b, err := json.Marshal(topo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back Topology
+	if err := json.Unmarshal(b, &back); err != nil {
+		t.Fatal(err)
+	} else if back.String() != topo.String() {
+		t.Errorf("JSON round trip changed topology:\n%s", back.String())
+	}
+}
+
+func TestTopologyString(t *testing.T) {
+	topo := &Topology{
+		Root: &TopologyNode{Type: "Gateway", DIN: "G--1", Firmware: "25.10.1", Children: []*TopologyNode{
+			{Type: "Powerwall", Role: "leader", DIN: "P--1", Children: []*TopologyNode{
+				{Type: "BMS", SerialNumber: "B1"},
+			}},
+			{Type: "Powerwall", Role: "follower", DIN: "P--2", Children: []*TopologyNode{
+				{Type: "BMS", SerialNumber: "B2"},
+			}},
+		}},
+		ExpectedPowerwalls: 2,
+		Enumerating:        true,
+		Warnings:           []string{"could not read remote meters: nope"},
+	}
+
+	want := `Gateway G--1 fw=25.10.1
+├── Powerwall (leader) P--1
+│   └── BMS serial=B1
+└── Powerwall (follower) P--2
+    └── BMS serial=B2
+expected Powerwalls=2 PVInverters=0 (enumerating)
+warning: could not read remote meters: nope
+`
+	if got := topo.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+
+	if got := (&Topology{}).String(); got != "expected Powerwalls=0 PVInverters=0\n" {
+		t.Errorf("empty: got %q", got)
+	}
+}
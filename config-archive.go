@@ -0,0 +1,292 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConfigArchive stores versioned snapshots of "config.json" in a local directory.
+// Snapshots are only written when the config actually changes.
+type ConfigArchive struct {
+	Dir string // created if needed
+}
+
+// ConfigSnapshot is a single stored version of "config.json".
+type ConfigSnapshot struct {
+	Version int       `json:"version"` // increasing from 1
+	Time    time.Time `json:"time"`
+	Path    string    `json:"path"`
+}
+
+// ConfigChange is a single semantic difference between two configs.
+type ConfigChange struct {
+	Path string `json:"path"` // e.g., "site_info.backup_reserve_percent" or "meters[1].type"
+	Op   string `json:"op"`   // "added", "removed" or "changed"
+	Old  any    `json:"old,omitzero"`
+	New  any    `json:"new,omitzero"`
+}
+
+func (c ConfigChange) String() string {
+	switch c.Op {
+	case "added":
+		return fmt.Sprintf("+ %s = %v", c.Path, c.New)
+	case "removed":
+		return fmt.Sprintf("- %s = %v", c.Path, c.Old)
+	}
+	return fmt.Sprintf("~ %s: %v => %v", c.Path, c.Old, c.New)
+}
+
+// Capture fetches "config.json" and stores it as a new snapshot if it differs from the latest.
+// Returns the latest snapshot, and whether it was newly written.
+func (a *ConfigArchive) Capture(ctx context.Context, td *TEDApi) (snap *ConfigSnapshot, changed bool, err error) {
+	out, err := td.Config(ctx, "config.json")
+	if err != nil {
+		return nil, false, err
+	}
+	return a.Store(out, time.Now())
+}
+
+// Store adds the given config as a new snapshot if it's semantically different from the latest.
+func (a *ConfigArchive) Store(config []byte, now time.Time) (snap *ConfigSnapshot, changed bool, err error) {
+	all, err := a.List()
+	if err != nil {
+		return nil, false, err
+	}
+
+	version := 1
+	if len(all) != 0 {
+		latest := all[len(all)-1]
+		prev, err := os.ReadFile(latest.Path)
+		if err != nil {
+			return nil, false, err
+		}
+		changes, err := DiffConfig(prev, config)
+		if err != nil {
+			return nil, false, err
+		} else if len(changes) == 0 {
+			return &latest, false, nil
+		}
+		version = latest.Version + 1
+	}
+
+	err = os.MkdirAll(a.Dir, 0o700)
+	if err != nil {
+		return nil, false, err
+	}
+
+	snap = &ConfigSnapshot{Version: version, Time: now.UTC().Truncate(time.Second)}
+	name := fmt.Sprintf("config-%06d-%s.json", snap.Version, snap.Time.Format("20060102T150405Z"))
+	snap.Path = filepath.Join(a.Dir, name)
+
+	// this contains the device password, so keep it private
+	err = os.WriteFile(snap.Path, config, 0o600)
+	if err != nil {
+		return nil, false, err
+	}
+	return snap, true, nil
+}
+
+// List returns all stored snapshots, oldest first.
+func (a *ConfigArchive) List() (out []ConfigSnapshot, err error) {
+	entries, err := os.ReadDir(a.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		rest, ok := strings.CutPrefix(e.Name(), "config-")
+		if !ok {
+			continue
+		}
+		rest, ok = strings.CutSuffix(rest, ".json")
+		if !ok {
+			continue
+		}
+		versionPart, timePart, _ := strings.Cut(rest, "-")
+
+		version, err := strconv.Atoi(versionPart)
+		if err != nil {
+			continue
+		}
+		t, err := time.Parse("20060102T150405Z", timePart)
+		if err != nil {
+			continue
+		}
+		out = append(out, ConfigSnapshot{Version: version, Time: t, Path: filepath.Join(a.Dir, e.Name())})
+	}
+
+	slices.SortFunc(out, func(a, b ConfigSnapshot) int { return a.Version - b.Version })
+	return out, nil
+}
+
+// Diff returns the changes between two stored snapshots.
+func (a *ConfigArchive) Diff(from, to ConfigSnapshot) (changes []ConfigChange, err error) {
+	fromBytes, err := os.ReadFile(from.Path)
+	if err != nil {
+		return nil, err
+	}
+	toBytes, err := os.ReadFile(to.Path)
+	if err != nil {
+		return nil, err
+	}
+	return DiffConfig(fromBytes, toBytes)
+}
+
+// Run captures a snapshot every interval until the context is done.
+// If onChange is non-nil, it's called with the changes whenever a new snapshot is written (except the first).
+// Failed captures are logged to [TEDApi.Logger] and retried next interval.
+func (a *ConfigArchive) Run(ctx context.Context, td *TEDApi, interval time.Duration, onChange func(snap *ConfigSnapshot, changes []ConfigChange)) error {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		err := a.runOnce(ctx, td, onChange)
+		if err != nil {
+			td.logger().Warn("could not capture config", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+}
+
+func (a *ConfigArchive) runOnce(ctx context.Context, td *TEDApi, onChange func(snap *ConfigSnapshot, changes []ConfigChange)) error {
+	before, err := a.List()
+	if err != nil {
+		return err
+	}
+
+	snap, changed, err := a.Capture(ctx, td)
+	if err != nil || !changed || onChange == nil || len(before) == 0 {
+		return err
+	}
+
+	changes, err := a.Diff(before[len(before)-1], *snap)
+	if err != nil {
+		return err
+	}
+	onChange(snap, changes)
+	return nil
+}
+
+// DiffConfig returns the semantic differences between two JSON configs.
+// Whitespace and key order are ignored, and values under any "password" key are redacted.
+func DiffConfig(from, to []byte) (changes []ConfigChange, err error) {
+	var fromValue, toValue any
+	err = json.Unmarshal(from, &fromValue)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(to, &toValue)
+	if err != nil {
+		return nil, err
+	}
+
+	diffValue("", fromValue, toValue, false, &changes)
+	return changes, nil
+}
+
+func diffValue(path string, from, to any, redact bool, changes *[]ConfigChange) {
+	fromMap, ok1 := from.(map[string]any)
+	toMap, ok2 := to.(map[string]any)
+	if ok1 && ok2 {
+		keys := slices.Collect(maps.Keys(fromMap))
+		for k := range toMap {
+			if _, ok := fromMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+
+		for _, k := range keys {
+			childPath := k
+			if path != "" {
+				childPath = path + "." + k
+			}
+			childRedact := redact || strings.Contains(strings.ToLower(k), "password")
+
+			fromChild, inFrom := fromMap[k]
+			toChild, inTo := toMap[k]
+			switch {
+			case !inFrom:
+				*changes = append(*changes, ConfigChange{Path: childPath, Op: "added", New: redactValue(toChild, childRedact)})
+			case !inTo:
+				*changes = append(*changes, ConfigChange{Path: childPath, Op: "removed", Old: redactValue(fromChild, childRedact)})
+			default:
+				diffValue(childPath, fromChild, toChild, childRedact, changes)
+			}
+		}
+		return
+	}
+
+	fromArr, ok1 := from.([]any)
+	toArr, ok2 := to.([]any)
+	if ok1 && ok2 {
+		for i := range max(len(fromArr), len(toArr)) {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(fromArr):
+				*changes = append(*changes, ConfigChange{Path: childPath, Op: "added", New: redactValue(toArr[i], redact)})
+			case i >= len(toArr):
+				*changes = append(*changes, ConfigChange{Path: childPath, Op: "removed", Old: redactValue(fromArr[i], redact)})
+			default:
+				diffValue(childPath, fromArr[i], toArr[i], redact, changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(from, to) {
+		*changes = append(*changes, ConfigChange{
+			Path: path,
+			Op:   "changed",
+			Old:  redactValue(from, redact),
+			New:  redactValue(to, redact),
+		})
+	}
+}
+
+// redactValue returns v, or a redacted placeholder if it's under a "password" key (or contains one).
+func redactValue(v any, redact bool) any {
+	if redact {
+		return redacted
+	}
+	return redactNested(v)
+}
+
+// redactNested returns a copy of v with any values under "password" keys replaced.
+func redactNested(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, child := range v {
+			if strings.Contains(strings.ToLower(k), "password") {
+				out[k] = redacted
+			} else {
+				out[k] = redactNested(child)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, child := range v {
+			out[i] = redactNested(child)
+		}
+		return out
+	}
+	return v
+}
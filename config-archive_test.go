@@ -0,0 +1,52 @@
+package powerwall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []ConfigChange
+	}{
+		{"same", `{"a":1,"b":[1,2]}`, `{ "b": [1, 2], "a": 1 }`, nil},
+		{"changed", `{"site_info":{"backup_reserve_percent":20}}`, `{"site_info":{"backup_reserve_percent":30}}`, []ConfigChange{
+			{Path: "site_info.backup_reserve_percent", Op: "changed", Old: 20.0, New: 30.0},
+		}},
+		{"added and removed", `{"a":1}`, `{"b":2}`, []ConfigChange{
+			{Path: "a", Op: "removed", Old: 1.0},
+			{Path: "b", Op: "added", New: 2.0},
+		}},
+		{"array", `{"meters":[{"type":"neurio"}]}`, `{"meters":[{"type":"rgm"},{"type":"neurio"}]}`, []ConfigChange{
+			{Path: "meters[0].type", Op: "changed", Old: "neurio", New: "rgm"},
+			{Path: "meters[1]", Op: "added", New: map[string]any{"type": "neurio"}},
+		}},
+		{"type changed", `{"a":[1]}`, `{"a":{"b":1}}`, []ConfigChange{
+			{Path: "a", Op: "changed", Old: []any{1.0}, New: map[string]any{"b": 1.0}},
+		}},
+		{"password redacted", `{"credentials":{"password":"old"}}`, `{"credentials":{"password":"new"}}`, []ConfigChange{
+			{Path: "credentials.password", Op: "changed", Old: redacted, New: redacted},
+		}},
+		{"nested password redacted", `{}`, `{"installer":{"Password":"x","name":"y"}}`, []ConfigChange{
+			{Path: "installer", Op: "added", New: map[string]any{"Password": redacted, "name": "y"}},
+		}},
+		{"under password redacted", `{"passwords":["a"]}`, `{"passwords":["a","b"]}`, []ConfigChange{
+			{Path: "passwords[1]", Op: "added", New: redacted},
+		}},
+	}
+	for _, tt := range tests {
+		got, err := DiffConfig([]byte(tt.from), []byte(tt.to))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s:\n got %+v\nwant %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := DiffConfig([]byte(`{`), []byte(`{}`)); err == nil {
+		t.Errorf("expected error for bad JSON")
+	}
+}
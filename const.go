@@ -2,7 +2,9 @@ package powerwall
 
 // these are the three queries from pypowerwall (from some kind of extraction from firmware)
 // they have a signature that's from a private key Tesla has; you can't make new queries
-// it's unclear whether the interpolated variables are part of the sig
+// the signed message layout is unverified: Tesla's public key isn't known, so no real signature has been checked
+// VerifyQuery guesses the message is the query text then the vars, which may well be wrong
+// other signed queries (e.g., from firmware extractions) can be loaded at runtime with LoadQueryCatalog
 var (
 	// QueryStatus is a general query of the Powerwall leader.
 	// It returns total kW/kWh etc for the entire system, status and so on.
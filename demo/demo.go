@@ -16,22 +16,31 @@ var (
 	flagTeslaSecret = flag.String("gw_pw", "", "Powerwall secret")
 	flagMin         = flag.Float64("min", 100, "minimum wH to report status for")
 	flagRemote      = flag.String("host", "192.168.91.1:443", "default Tesla remote")
+	flagTimeout     = flag.Duration("timeout", time.Minute, "overall timeout")
 )
 
 func main() {
 	flag.Parse()
 
-	api := &powerwall.TEDApi{Secret: *flagTeslaSecret, Remote: *flagRemote}
-	status, err := powerwall.GetSimpleStatus(context.Background(), api)
+	ctx, cancel := context.WithTimeout(context.Background(), *flagTimeout)
+	defer cancel()
+
+	api := &powerwall.TEDApi{Secret: powerwall.Secret(*flagTeslaSecret), Remote: *flagRemote}
+	status, err := powerwall.GetSimpleStatus(ctx, api)
 	if err != nil {
 		log.Fatalf("could not read status: %v", err)
 	}
+	if err := status.CheckClockSkew(powerwall.DefaultMaxClockSkew); err != nil {
+		log.Printf("warning: %v", err)
+	}
 
 	byDevice := map[string]powerwall.SimpleDeviceStatus{}
 	if len(status.BatteryBlocks) > 1 {
 		// demo fetches individual devices only if you have multiple
-		for _, din := range status.BatteryBlocks {
-			res, err := powerwall.GetSimpleDeviceStatus(context.Background(), api, din)
+		for i, din := range status.BatteryBlocks {
+			deviceCtx, cancel := powerwall.Budget(ctx, len(status.BatteryBlocks)-i)
+			res, err := powerwall.GetSimpleDeviceStatus(deviceCtx, api, din)
+			cancel()
 			if err != nil {
 				log.Fatalf("failed to lookup device %s: %v", din, err)
 			}
@@ -92,7 +101,7 @@ func main() {
 		log.Printf("  SOLAR   %s (%s)", powerwall.FormatPowerTable(status.PowerSolar), strings.Join(mpptParts, " "))
 		log.Printf("  BATTERY %s", powerwall.FormatPowerTable(status.PowerBattery))
 		log.Printf("")
-		log.Printf("  %6.2fv %5.2fHz", status.Voltage, status.Freq)
+		log.Printf("  %s %5.2fHz %s (%v)", status.FormatVoltage(), status.Freq, status.Wiring, status.State)
 	}
 	log.Printf("")
 
@@ -0,0 +1,41 @@
+package powerwall
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// DialFunc makes a network connection, like [net.Dialer.DialContext].
+// Set [TEDApi.Dial] to control how the gateway is reached.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// BindDialer returns a [DialFunc] that connects from the given local IP address.
+// On a multi-homed host, use this to appear on the gateway's 192.168.91.x subnet without NAT rules.
+func BindDialer(localIP string) (DialFunc, error) {
+	ip := net.ParseIP(localIP)
+	if ip == nil {
+		return nil, fmt.Errorf("bad local IP: %q", localIP)
+	}
+	d := &net.Dialer{LocalAddr: &net.TCPAddr{IP: ip}}
+	return d.DialContext, nil
+}
+
+// InterfaceDialer returns a [DialFunc] that connects from the first IPv4 address of the named interface (e.g., "wlan0").
+// The address is resolved now, so recreate the dialer if it changes.
+func InterfaceDialer(name string) (DialFunc, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range addrs {
+		if ipnet, ok := a.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			return BindDialer(ipnet.IP.String())
+		}
+	}
+	return nil, fmt.Errorf("no IPv4 address on interface %q", name)
+}
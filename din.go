@@ -0,0 +1,258 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+var (
+	dinPattern = regexp.MustCompile(`^[0-9A-Z]+-[0-9A-Z]+-[0-9A-Z]+--[0-9A-Z]+$`)
+)
+
+// ValidateDIN checks that din looks like a Tesla DIN, e.g., "1232100-00-E--TG123456789012".
+func ValidateDIN(din string) error {
+	if !dinPattern.MatchString(din) {
+		return fmt.Errorf("invalid DIN: %q", din)
+	}
+	return nil
+}
+
+// DINCache persists leader DINs between runs, so a [TEDApi] doesn't need to look it up each time.
+// Entries are keyed by remote ([TEDApi.BaseURL] or [TEDApi.Remote]). Storing a blank DIN forgets the entry.
+type DINCache interface {
+	LoadDIN(remote string) (din string, ok bool)
+	StoreDIN(remote, din string) error
+}
+
+// FileDINCache is a [DINCache] stored as a JSON object in the named file.
+type FileDINCache string
+
+var fileDINCacheLock sync.Mutex
+
+func (f FileDINCache) read() map[string]string {
+	out := map[string]string{}
+	b, err := os.ReadFile(string(f))
+	if err == nil {
+		json.Unmarshal(b, &out)
+	}
+	return out
+}
+
+func (f FileDINCache) LoadDIN(remote string) (din string, ok bool) {
+	fileDINCacheLock.Lock()
+	defer fileDINCacheLock.Unlock()
+
+	din, ok = f.read()[remote]
+	return din, ok
+}
+
+func (f FileDINCache) StoreDIN(remote, din string) error {
+	fileDINCacheLock.Lock()
+	defer fileDINCacheLock.Unlock()
+
+	all := f.read()
+	if din == "" {
+		delete(all, remote)
+	} else {
+		all[remote] = din
+	}
+	b, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write-then-rename so a crash doesn't leave a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(string(f)), ".din-cache-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), string(f))
+}
+
+// dinCall is an in-flight DIN lookup.
+type dinCall struct {
+	inflight[string]
+	gen uint64 // of the DIN when the lookup started; if it's since been forgotten, the result isn't kept
+}
+
+// LeaderDIN returns the DIN of the leader: [TEDApi.DIN] if set, otherwise the cached or looked-up value.
+func (td *TEDApi) LeaderDIN(ctx context.Context) (din string, err error) {
+	return td.getDIN(ctx)
+}
+
+// ForgetDIN clears the leader DIN (including from [TEDApi.DINCache]), so it's looked up again on next use.
+// This happens automatically if a reply comes from a different leader, or if a message is rejected and the gateway
+// now reports a different DIN. It has no effect if [TEDApi.DIN] is set.
+func (td *TEDApi) ForgetDIN() {
+	td.forgetDIN("")
+}
+
+// forgetDIN clears the leader DIN, but only if it's still stale (any DIN, if stale is blank).
+// Any lookup already in flight is abandoned, so it can't store a DIN from before the forget.
+func (td *TEDApi) forgetDIN(stale string) {
+	if td.DIN != "" {
+		return
+	}
+
+	td.lock.Lock()
+	had := td.internalDIN
+	if stale != "" && had != stale {
+		td.lock.Unlock()
+		return // already replaced
+	}
+	td.internalDIN = ""
+	td.dinGen++
+	td.dinCall = nil
+	gen := td.dinGen
+	td.lock.Unlock()
+
+	if err := td.storeCachedDIN(gen, ""); err != nil {
+		td.logger().Warn("could not clear cached DIN", "err", err)
+	}
+	if had != "" {
+		td.logger().Info("forgot leader DIN", "din", had)
+	}
+}
+
+// storeCachedDIN writes din to the [DINCache], unless the DIN has been forgotten since gen (that forget clears the
+// cache itself). Stores are serialized, so a lookup's store can't land after a later forget's clear; at worst, a
+// clear lands after a newer lookup's store, and the next run looks it up again.
+func (td *TEDApi) storeCachedDIN(gen uint64, din string) error {
+	if td.DINCache == nil {
+		return nil
+	}
+
+	td.dinCacheLock.Lock()
+	defer td.dinCacheLock.Unlock()
+
+	td.lock.Lock()
+	current := td.dinGen == gen
+	td.lock.Unlock()
+	if !current {
+		return nil
+	}
+	return td.DINCache.StoreDIN(td.remote(), din)
+}
+
+// getDIN returns the user-provided DIN, or does a cached lookup on the device's DIN by making an API request.
+// Concurrent callers share one lookup, but each can give up via its own ctx.
+func (td *TEDApi) getDIN(ctx context.Context) (din string, err error) {
+	if td.DIN != "" {
+		return td.DIN, nil
+	}
+
+	td.lock.Lock()
+	if td.internalDIN != "" {
+		din = td.internalDIN
+		td.lock.Unlock()
+		return din, nil
+	}
+
+	call := td.dinCall
+	if call == nil {
+		call = &dinCall{gen: td.dinGen}
+		td.dinCall = call
+		call.start(ctx, td.lookupTimeout(), func(ctx context.Context) (string, error) {
+			return td.fetchDIN(ctx, call)
+		})
+	}
+	td.lock.Unlock()
+
+	return call.wait(ctx)
+}
+
+// fetchDIN runs a shared lookup, from the [DINCache] if it has a valid entry, otherwise from the gateway.
+func (td *TEDApi) fetchDIN(ctx context.Context, call *dinCall) (din string, err error) {
+	din, cached := td.loadCachedDIN()
+	if !cached {
+		var body []byte
+		body, _, err = td.internalRequest(ctx, "/tedapi/din", nil)
+		din, err = parseDIN(body, err)
+	}
+
+	td.lock.Lock()
+	if td.dinCall == call {
+		td.dinCall = nil
+	}
+	keep := err == nil && td.dinGen == call.gen
+	if keep {
+		td.internalDIN = din
+	}
+	td.lock.Unlock()
+
+	if keep && !cached {
+		td.logger().Debug("got leader DIN", "din", din)
+		if storeErr := td.storeCachedDIN(call.gen, din); storeErr != nil {
+			td.logger().Warn("could not cache DIN", "err", storeErr)
+		}
+	}
+
+	return din, err
+}
+
+// loadCachedDIN returns the leader DIN from the [DINCache], if it has a valid entry.
+func (td *TEDApi) loadCachedDIN() (din string, ok bool) {
+	if td.DINCache == nil {
+		return "", false
+	}
+
+	td.dinCacheLock.Lock()
+	defer td.dinCacheLock.Unlock()
+
+	din, ok = td.DINCache.LoadDIN(td.remote())
+	return din, ok && ValidateDIN(din) == nil
+}
+
+// probeDIN asks a specific remote for the leader's DIN, without side effects (see [TEDApi.probe]).
+func (td *TEDApi) probeDIN(ctx context.Context, remote string) (din string, err error) {
+	return parseDIN(td.probe(ctx, remote, "/tedapi/din"))
+}
+
+// parseDIN validates the body of a DIN lookup, passing through err.
+func parseDIN(body []byte, err error) (din string, _ error) {
+	if err != nil {
+		return "", err
+	}
+	din = strings.TrimSpace(string(body))
+	if err := ValidateDIN(din); err != nil {
+		return "", fmt.Errorf("bad DIN from API: %w", err)
+	}
+	return din, nil
+}
+
+// recheckDIN is called after a message to the leader was rejected, forgetting the DIN it was addressed to
+// if the gateway now reports a different one. A rejection that isn't about the DIN (e.g., a bad secret) leaves it.
+func (td *TEDApi) recheckDIN(ctx context.Context, remote string, req *protocol.Message) {
+	addressed := ParticipantString(req.GetMessage().GetRecipient())
+	if td.DIN != "" || addressed == "" {
+		return
+	}
+	din, err := td.probeDIN(ctx, remote)
+	if err == nil && din != addressed {
+		td.logger().Info("leader DIN changed", "from", addressed, "to", din)
+		td.forgetDIN(addressed)
+	}
+}
+
+// isLeaderSwap returns whether err shows that a reply to the leader came from another device.
+func isLeaderSwap(err error, customDin string) bool {
+	var envErr *EnvelopeError
+	return customDin == "" && errors.As(err, &envErr) && envErr.Field == "sender" && envErr.Got != ""
+}
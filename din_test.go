@@ -0,0 +1,149 @@
+package powerwall
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// memoryDINCache is a [DINCache] in memory.
+type memoryDINCache struct {
+	lock sync.Mutex
+	dins map[string]string
+}
+
+func (m *memoryDINCache) LoadDIN(remote string) (din string, ok bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	din, ok = m.dins[remote]
+	return din, ok
+}
+
+func (m *memoryDINCache) StoreDIN(remote, din string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.dins == nil {
+		m.dins = map[string]string{}
+	}
+	if din == "" {
+		delete(m.dins, remote)
+	} else {
+		m.dins[remote] = din
+	}
+	return nil
+}
+
+func TestForgetDINDuringLookup(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-release
+		io.WriteString(w, fakeLeaderDIN)
+	}))
+	t.Cleanup(s.Close)
+
+	cache := &memoryDINCache{}
+	td := &TEDApi{BaseURL: s.URL, Secret: "secret", DINCache: cache}
+	done := make(chan error)
+	go func() {
+		_, err := td.LeaderDIN(context.Background())
+		done <- err
+	}()
+
+	// forget while the lookup is in flight
+	<-started
+	td.ForgetDIN()
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	td.lock.Lock()
+	defer td.lock.Unlock()
+	if td.internalDIN != "" {
+		t.Errorf("stale lookup stored DIN %q after forget", td.internalDIN)
+	}
+	if din, ok := cache.LoadDIN(td.remote()); ok {
+		t.Errorf("stale lookup cached DIN %q after forget", din)
+	}
+}
+
+func TestDINCache(t *testing.T) {
+	var lookups atomic.Int32
+	s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		io.WriteString(w, fakeLeaderDIN)
+	}))
+	t.Cleanup(s.Close)
+
+	cache := &memoryDINCache{}
+	cache.StoreDIN(s.URL, "not a DIN")
+
+	// an invalid entry is looked up and replaced
+	td := &TEDApi{BaseURL: s.URL, Secret: "secret", DINCache: cache}
+	if din, err := td.LeaderDIN(context.Background()); err != nil || din != fakeLeaderDIN {
+		t.Fatalf("got %q, %v", din, err)
+	}
+	if din, _ := cache.LoadDIN(s.URL); din != fakeLeaderDIN || lookups.Load() != 1 {
+		t.Errorf("got cached %q after %d lookups", din, lookups.Load())
+	}
+
+	// a new TEDApi uses the cached entry
+	td = &TEDApi{BaseURL: s.URL, Secret: "secret", DINCache: cache}
+	if din, err := td.LeaderDIN(context.Background()); err != nil || din != fakeLeaderDIN {
+		t.Fatalf("got %q, %v", din, err)
+	}
+	if lookups.Load() != 1 {
+		t.Errorf("got %d lookups, want 1", lookups.Load())
+	}
+
+	td.ForgetDIN()
+	if din, ok := cache.LoadDIN(s.URL); ok {
+		t.Errorf("got cached %q after forget", din)
+	}
+}
+
+func TestRecheckDINOnRejection(t *testing.T) {
+	const other = "1707000-11-J--TG000000000000"
+
+	tests := []struct {
+		name       string
+		gatewayDIN string
+		want       string // known DIN afterwards
+		wantLog    string
+	}{
+		{"same leader", fakeLeaderDIN, fakeLeaderDIN, ""},
+		{"new leader", other, "", "leader DIN changed"},
+	}
+	for _, tt := range tests {
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /tedapi/din", func(w http.ResponseWriter, r *http.Request) {
+			io.WriteString(w, tt.gatewayDIN)
+		})
+		mux.HandleFunc("POST /tedapi/v1", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		})
+		s := httptest.NewTLSServer(mux)
+
+		var logs bytes.Buffer
+		td := &TEDApi{BaseURL: s.URL, Secret: "secret", internalDIN: fakeLeaderDIN, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+		if _, err := td.Firmware(context.Background()); !isAuthFailure(err) {
+			t.Errorf("%s: got %v, want 403", tt.name, err)
+		}
+		if td.internalDIN != tt.want {
+			t.Errorf("%s: got DIN %q, want %q", tt.name, td.internalDIN, tt.want)
+		}
+		if got := logs.String(); !strings.Contains(got, tt.wantLog) || (tt.wantLog == "" && got != "") {
+			t.Errorf("%s: got logs %q, want %q", tt.name, got, tt.wantLog)
+		}
+		s.Close()
+	}
+}
@@ -0,0 +1,314 @@
+package powerwall
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+var (
+	gatewaySubnet = &net.IPNet{IP: net.IPv4(192, 168, 91, 0), Mask: net.CIDRMask(24, 32)}
+)
+
+// DoctorStep is the result of one check run by [Diagnose].
+type DoctorStep struct {
+	Name     string        `json:"name"`
+	OK       bool          `json:"ok"`
+	Skipped  bool          `json:"skipped,omitzero"` // an earlier step failed
+	Detail   string        `json:"detail,omitzero"`
+	Hint     string        `json:"hint,omitzero"` // what to try, if it failed
+	Duration time.Duration `json:"duration,omitzero"`
+}
+
+// DoctorReport is the result of [Diagnose].
+type DoctorReport struct {
+	Steps []DoctorStep `json:"steps"`
+}
+
+// OK returns whether every step passed.
+func (r *DoctorReport) OK() bool {
+	for _, s := range r.Steps {
+		if !s.OK {
+			return false
+		}
+	}
+	return true
+}
+
+// String renders the report as a pass/fail list.
+func (r *DoctorReport) String() string {
+	var sb strings.Builder
+	for _, s := range r.Steps {
+		status := "PASS"
+		if s.Skipped {
+			status = "SKIP"
+		} else if !s.OK {
+			status = "FAIL"
+		}
+		fmt.Fprintf(&sb, "%s %s", status, s.Name)
+		if s.Detail != "" {
+			fmt.Fprintf(&sb, ": %s", s.Detail)
+		}
+		if d := s.Duration.Round(time.Millisecond); d > 0 {
+			fmt.Fprintf(&sb, " (%v)", d)
+		}
+		sb.WriteByte('\n')
+		if s.Hint != "" && !s.OK && !s.Skipped {
+			fmt.Fprintf(&sb, "     hint: %s\n", s.Hint)
+		}
+	}
+	return sb.String()
+}
+
+// doctor accumulates steps, skipping the rest of a group once one fails.
+type doctor struct {
+	report   *DoctorReport
+	failed   bool
+	onSubnet bool // whether we're connecting from 192.168.91.x; true if unknown
+}
+
+func (d *doctor) run(name string, fn func() (detail, hint string, err error)) bool {
+	if d.failed {
+		d.report.Steps = append(d.report.Steps, DoctorStep{Name: name, Skipped: true})
+		return false
+	}
+
+	start := time.Now()
+	detail, hint, err := fn()
+	step := DoctorStep{Name: name, OK: err == nil, Detail: detail, Hint: hint, Duration: time.Since(start)}
+	if err != nil {
+		step.Detail = err.Error()
+		if detail != "" {
+			step.Detail = fmt.Sprintf("%s: %v", detail, err)
+		}
+		d.failed = true
+	}
+	d.report.Steps = append(d.report.Steps, step)
+	return err == nil
+}
+
+// Diagnose checks connectivity to the gateway step by step, for new setups and support requests.
+// For each remote it checks the route, TCP, TLS and "/tedapi/din"; then it checks auth, a signed [QueryStatus],
+// and routing to every battery block.
+// Steps after a failure are skipped. This never returns an error; failures are reported as steps.
+// The checks before the query don't refresh the secret or forget the DIN, so they report on td as configured.
+func Diagnose(ctx context.Context, td *TEDApi) *DoctorReport {
+	report := &DoctorReport{}
+
+	var reachable []string
+	anyOffSubnet := false
+	for _, remote := range td.remotes() {
+		d := &doctor{report: report, onSubnet: true}
+		d.diagnoseRemote(ctx, td, remote)
+		if !d.failed {
+			reachable = append(reachable, remote)
+		}
+		anyOffSubnet = anyOffSubnet || !d.onSubnet
+	}
+
+	d := &doctor{report: report, failed: len(reachable) == 0, onSubnet: !anyOffSubnet}
+	d.diagnoseGateway(ctx, td, reachable)
+	return report
+}
+
+func (d *doctor) diagnoseRemote(ctx context.Context, td *TEDApi, remote string) {
+	var u *urlParts
+	d.run(fmt.Sprintf("route %s", remote), func() (string, string, error) {
+		var err error
+		u, err = splitRemote(remote)
+		if err != nil {
+			return "", "check Remote, BaseURL or Remotes", err
+		}
+
+		var detail string
+		if net.ParseIP(u.host) == nil {
+			addrs, err := net.DefaultResolver.LookupHost(ctx, u.host)
+			if err != nil {
+				return "DNS", "check the hostname, or use the gateway's IP", err
+			}
+			detail = fmt.Sprintf("%s resolves to %s", u.host, strings.Join(addrs, ", "))
+		} else {
+			detail = u.host
+		}
+
+		if td.Proxy != "" {
+			return detail + ", via proxy", "", nil
+		} else if td.Dial != nil {
+			return detail + ", via custom dialer", "", nil
+		}
+
+		// a UDP "connection" sends nothing, but reveals the source address the OS would use
+		conn, err := net.Dial("udp", u.addr)
+		if err != nil {
+			return detail, "no route to the gateway: join its WiFi or add a route", err
+		}
+		local := conn.LocalAddr().(*net.UDPAddr).IP
+		conn.Close()
+		detail += fmt.Sprintf(", from %s", local)
+
+		if remoteIP := net.ParseIP(u.host); remoteIP != nil && gatewaySubnet.Contains(remoteIP) && !gatewaySubnet.Contains(local) {
+			d.onSubnet = false
+			return detail + " (not on 192.168.91.x)", "", nil
+		}
+		return detail, "", nil
+	})
+
+	var conn net.Conn
+	d.run(fmt.Sprintf("tcp %s", remote), func() (string, string, error) {
+		if td.Proxy != "" {
+			return "via proxy, checked by the DIN lookup below", "", nil
+		}
+		var err error
+		conn, err = withConnectTimeout(td.Dial, td.connectTimeout())(ctx, "tcp", u.addr)
+		if err != nil {
+			return "", "the gateway (or WiFi dongle) may be down, or a firewall is in the way", err
+		}
+		return fmt.Sprintf("connected to %s", conn.RemoteAddr()), "", nil
+	})
+
+	d.run(fmt.Sprintf("tls %s", remote), func() (string, string, error) {
+		if conn == nil {
+			return "via proxy, checked by the DIN lookup below", "", nil
+		}
+		defer conn.Close()
+		if u.scheme != "https" {
+			return "plain HTTP, not checked", "", nil
+		}
+
+		tlsCtx, cancel := context.WithTimeout(ctx, td.tlsTimeout())
+		defer cancel()
+		tlsConn := tls.Client(conn, &tls.Config{InsecureSkipVerify: true, ServerName: u.host})
+		err := tlsConn.HandshakeContext(tlsCtx)
+		if err != nil {
+			return "", "something other than the gateway may be answering on this port", err
+		}
+
+		state := tlsConn.ConnectionState()
+		detail := tls.VersionName(state.Version)
+		if len(state.PeerCertificates) != 0 {
+			cert := state.PeerCertificates[0]
+			detail += fmt.Sprintf(", cert CN=%q issuer=%q expires %s", cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.Format(time.DateOnly))
+			if len(cert.DNSNames) != 0 {
+				detail += fmt.Sprintf(" names=%s", strings.Join(cert.DNSNames, ","))
+			}
+		}
+		return detail, "", nil
+	})
+
+	d.run(fmt.Sprintf("din %s", remote), func() (string, string, error) {
+		body, err := td.probe(ctx, remote, "/tedapi/din")
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			// the gateway answered; whether we're allowed in is the auth step's job
+			return fmt.Sprintf("reachable, status %s", statusErr.Status), "", nil
+		} else if err != nil {
+			return "", "", err
+		}
+		din := strings.TrimSpace(string(body))
+		if err := ValidateDIN(din); err != nil {
+			return "", "this may not be a Powerwall gateway", err
+		}
+		return din, "", nil
+	})
+}
+
+func (d *doctor) diagnoseGateway(ctx context.Context, td *TEDApi, reachable []string) {
+	d.run("auth", func() (string, string, error) {
+		remote := reachable[0]
+		_, err := td.probe(ctx, remote, "/tedapi/din")
+		if err == nil {
+			return "secret accepted", "", nil
+		} else if !isAuthFailure(err) {
+			return "", "", err
+		}
+
+		// compare against a secret that's certainly wrong: if that's rejected the same way from off-subnet, blame the subnet
+		bogus := td.withSecret("doctor-invalid-secret")
+		_, bogusErr := bogus.probe(ctx, remote, "/tedapi/din")
+		if isAuthFailure(bogusErr) && bogusErr.Error() == err.Error() && !d.onSubnet {
+			return "rejected regardless of secret", "the gateway only accepts clients on 192.168.91.x: see the README's Network Access section", err
+		}
+		return "secret rejected", "check the secret printed under your Powerwall's casing", err
+	})
+
+	var blocks []string
+	d.run("query status", func() (string, string, error) {
+		res, err := td.QueryDeviceResult(ctx, QueryStatus, "")
+		if errors.Is(err, ErrBadSignature) {
+			return "", "the firmware may no longer accept this query's signature", err
+		} else if err != nil {
+			return "", "", err
+		} else if res.Data == nil {
+			return "", "", res.Errors
+		}
+
+		var status StatusResponse
+		err = json.Unmarshal(res.Data, &status)
+		if err != nil {
+			return "", "", err
+		}
+		for _, bb := range status.Control.BatteryBlocks {
+			blocks = append(blocks, bb.DIN)
+		}
+		return fmt.Sprintf("leader %s via %s, %d battery blocks", res.Envelope.Sender, res.Envelope.Remote, len(blocks)), "", nil
+	})
+
+	for i, din := range blocks {
+		d.failed = false // each block is independent
+		d.run(fmt.Sprintf("follower %s", din), func() (string, string, error) {
+			blockCtx, cancel := Budget(ctx, len(blocks)-i)
+			defer cancel()
+			res, err := td.QueryDeviceResult(blockCtx, QueryComponents, din)
+			if errors.Is(err, ErrEnvelopeMismatch) {
+				return "", "the reply came from another device; check the system's wiring and commissioning", err
+			} else if err != nil {
+				return "", "this device may be offline, or not reachable from the leader", err
+			} else if res.Data == nil {
+				return "", "", res.Errors
+			}
+			return fmt.Sprintf("routed via %s", res.Envelope.Path), "", nil
+		})
+	}
+}
+
+// withSecret returns a [TEDApi] that connects the same way as td (remotes, dialer, proxy and timeouts), but with
+// another secret. Nothing else is shared, so using it has no effect on td.
+func (td *TEDApi) withSecret(secret Secret) *TEDApi {
+	return &TEDApi{
+		DIN:            td.DIN,
+		Secret:         secret,
+		Remote:         td.Remote,
+		BaseURL:        td.BaseURL,
+		Remotes:        td.Remotes,
+		Dial:           td.Dial,
+		Proxy:          td.Proxy,
+		ConnectTimeout: td.ConnectTimeout,
+		TLSTimeout:     td.TLSTimeout,
+		Timeout:        td.Timeout,
+	}
+}
+
+type urlParts struct {
+	scheme, host, addr string
+}
+
+// splitRemote returns the scheme, host and dialable address of a remote.
+func splitRemote(remote string) (*urlParts, error) {
+	u, err := baseURL(remote)
+	if err != nil {
+		return nil, err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return &urlParts{scheme: u.Scheme, host: u.Hostname(), addr: net.JoinHostPort(u.Hostname(), port)}, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/samthor/powerwall"
+)
+
+var (
+	flagTeslaSecret = flag.String("gw_pw", "", "Powerwall secret")
+	flagRemote      = flag.String("host", "192.168.91.1:443", "default Tesla remote")
+	flagBaseURL     = flag.String("base_url", "", "URL to reach the gateway at, e.g., via a reverse proxy (replaces -host)")
+	flagRemotes     = flag.String("remotes", "", "comma-separated hosts or URLs to reach the gateway at (replaces -host and -base_url)")
+	flagProxy       = flag.String("proxy", "", "SOCKS5 or HTTP CONNECT proxy URL to reach the gateway through")
+	flagTimeout     = flag.Duration("timeout", time.Minute, "overall timeout")
+)
+
+func main() {
+	flag.Parse()
+
+	api := &powerwall.TEDApi{
+		Secret:  powerwall.Secret(*flagTeslaSecret),
+		Remote:  *flagRemote,
+		BaseURL: *flagBaseURL,
+		Proxy:   *flagProxy,
+	}
+	if *flagRemotes != "" {
+		api.Remotes = strings.Split(*flagRemotes, ",")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *flagTimeout)
+	defer cancel()
+
+	report := powerwall.Diagnose(ctx, api)
+	fmt.Print(report.String())
+	if !report.OK() {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,160 @@
+package powerwall
+
+import (
+	"context"
+	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+func TestSplitRemote(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   *urlParts // nil if an error
+	}{
+		{"192.168.91.1:443", &urlParts{"https", "192.168.91.1", "192.168.91.1:443"}},
+		{"gateway.local", &urlParts{"https", "gateway.local", "gateway.local:443"}},
+		{"fe80::1%eth0", &urlParts{"https", "fe80::1%eth0", "[fe80::1%eth0]:443"}},
+		{"[2001:db8::1]:8443", &urlParts{"https", "2001:db8::1", "[2001:db8::1]:8443"}},
+		{"http://proxy.local/site-a", &urlParts{"http", "proxy.local", "proxy.local:80"}},
+		{"https://proxy.local:8443/site-a/tedapi/v1", &urlParts{"https", "proxy.local", "proxy.local:8443"}},
+		{"ftp://proxy.local", nil},
+	}
+	for _, tt := range tests {
+		got, err := splitRemote(tt.remote)
+		if tt.want == nil {
+			if err == nil {
+				t.Errorf("%s: got %+v, want error", tt.remote, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.remote, err)
+		} else if *got != *tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.remote, *got, *tt.want)
+		}
+	}
+}
+
+func TestDoctorReportString(t *testing.T) {
+	r := &DoctorReport{Steps: []DoctorStep{
+		{Name: "route 192.168.91.1:443", OK: true, Detail: "192.168.91.1", Duration: 1500 * time.Microsecond},
+		{Name: "auth", Detail: "secret rejected: non-200 status: 401 Unauthorized", Hint: "check the secret"},
+		{Name: "query status", Skipped: true, Hint: "not shown"},
+		{Name: "follower x", OK: true, Hint: "not shown"},
+	}}
+	want := `PASS route 192.168.91.1:443: 192.168.91.1 (2ms)
+FAIL auth: secret rejected: non-200 status: 401 Unauthorized
+     hint: check the secret
+SKIP query status
+PASS follower x
+`
+	if got := r.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if r.OK() {
+		t.Errorf("report with a failure is OK")
+	}
+	if !(&DoctorReport{Steps: r.Steps[:1]}).OK() {
+		t.Errorf("report without failures isn't OK")
+	}
+}
+
+func TestWithSecretCopiesTransport(t *testing.T) {
+	td := &TEDApi{
+		DIN:            fakeLeaderDIN,
+		Secret:         "secret",
+		Remote:         "192.168.91.1:443",
+		BaseURL:        "https://proxy.local/site-a",
+		Remotes:        []string{"192.168.91.1", "gateway.local"},
+		SecretProvider: Secret("other"),
+		Dial:           (&net.Dialer{}).DialContext,
+		Proxy:          "socks5://jump.local:1080",
+		ConnectTimeout: time.Second,
+		TLSTimeout:     2 * time.Second,
+		Timeout:        3 * time.Second,
+		DINCache:       FileDINCache("din.json"),
+	}
+	got := td.withSecret("bogus")
+	if got.Secret != "bogus" || got.SecretProvider != nil || got.DINCache != nil {
+		t.Errorf("got secret %v, provider %v, cache %v", got.Secret, got.SecretProvider, got.DINCache)
+	}
+
+	// every other exported field affects how we connect, so must be copied
+	skip := []string{"Secret", "SecretProvider", "DINCache", "Logger"}
+	want, gotV := reflect.ValueOf(td).Elem(), reflect.ValueOf(got).Elem()
+	for i := range want.NumField() {
+		field := want.Type().Field(i)
+		if !field.IsExported() || slices.Contains(skip, field.Name) {
+			continue
+		}
+		a, b := want.Field(i), gotV.Field(i)
+		if field.Type.Kind() == reflect.Func {
+			if a.Pointer() != b.Pointer() {
+				t.Errorf("%s wasn't copied", field.Name)
+			}
+		} else if !reflect.DeepEqual(a.Interface(), b.Interface()) {
+			t.Errorf("%s: got %v, want %v", field.Name, b.Interface(), a.Interface())
+		}
+	}
+}
+
+func TestDiagnose(t *testing.T) {
+	const follower = "1707000-11-J--TG000000000000"
+	status := `{"control":{"batteryBlocks":[{"din":"` + fakeLeaderDIN + `"},{"din":"` + follower + `"}]}}`
+	s := newMessageGateway(t, func(req *protocol.MessageEnvelope) *protocol.MessageEnvelope {
+		reply := status
+		if ParticipantString(req.GetRecipient()) != fakeLeaderDIN {
+			reply = `{"components":{}}`
+		}
+		return &protocol.MessageEnvelope{Payload: &protocol.QueryType{Recv: &protocol.PayloadString{Text: reply}}}
+	})
+
+	proxy, tunnels := newConnectProxy(t)
+
+	ok := []string{
+		"PASS route", "PASS tcp", "PASS tls", "PASS din", "PASS auth", "PASS query status",
+		"PASS follower " + fakeLeaderDIN, "PASS follower " + follower,
+	}
+	tests := []struct {
+		name   string
+		secret Secret
+		proxy  string
+		want   []string // status and name of each step
+	}{
+		{"ok", "secret", "", ok},
+		{"via proxy", "secret", proxy.URL, ok},
+		{"bad secret", "wrong", "", []string{
+			"PASS route", "PASS tcp", "PASS tls", "PASS din", "FAIL auth", "SKIP query status",
+		}},
+	}
+	for _, tt := range tests {
+		td := &TEDApi{BaseURL: s.URL, Secret: tt.secret, Proxy: tt.proxy}
+		report := Diagnose(context.Background(), td)
+
+		var got []string
+		for _, step := range report.Steps {
+			status := "PASS"
+			if step.Skipped {
+				status = "SKIP"
+			} else if !step.OK {
+				status = "FAIL"
+			}
+			got = append(got, status+" "+strings.TrimSuffix(step.Name, " "+s.URL))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got steps %q, want %q\n%s", tt.name, got, tt.want, report)
+		}
+		if report.OK() != (tt.secret == "secret") {
+			t.Errorf("%s: got OK=%v", tt.name, report.OK())
+		}
+	}
+	if tunnels.Load() == 0 {
+		t.Errorf("didn't connect via proxy")
+	}
+}
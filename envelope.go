@@ -0,0 +1,108 @@
+package powerwall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+var (
+	// ErrEnvelopeMismatch is matched by errors where a response's envelope doesn't correspond to its request.
+	// In multi-Powerwall systems, this usually means a reply came from the wrong device.
+	ErrEnvelopeMismatch = errors.New("response envelope mismatch")
+)
+
+// Envelope describes the routing of a response from the gateway.
+type Envelope struct {
+	Remote          string `json:"remote"`    // which of the [TEDApi]'s remotes served the request
+	Path            string `json:"path"`      // HTTP path the request was posted to
+	DeliveryChannel int32  `json:"channel"`   // zero if not reported
+	Sender          string `json:"sender"`    // see [ParticipantString]
+	Recipient       string `json:"recipient"` // see [ParticipantString]
+	Tail            int32  `json:"tail"`      // zero if not reported
+}
+
+// EnvelopeError is returned when a response's envelope doesn't correspond to its request.
+type EnvelopeError struct {
+	Field     string // "sender", "recipient", "channel" or "tail"
+	Want, Got string
+	Envelope  Envelope
+}
+
+func (e *EnvelopeError) Error() string {
+	return fmt.Sprintf("response envelope %s mismatch: want=%s got=%s", e.Field, e.Want, e.Got)
+}
+
+func (e *EnvelopeError) Is(target error) bool {
+	return target == ErrEnvelopeMismatch
+}
+
+// ParticipantString formats a participant: its DIN, or "local", "authorizedClient", "teslaService" or "" (absent).
+func ParticipantString(p *protocol.Participant) string {
+	switch id := p.GetId().(type) {
+	case *protocol.Participant_Din:
+		return id.Din
+	case *protocol.Participant_Local:
+		return "local"
+	case *protocol.Participant_AuthorizedClient:
+		return "authorizedClient"
+	case *protocol.Participant_TeslaService:
+		return "teslaService"
+	}
+	return ""
+}
+
+// CheckEnvelope compares a response to the request it answers, returning the response's [Envelope].
+// The response should come from the request's recipient, be addressed to its sender, and use the same channel and tail.
+// Fields the gateway leaves unset aren't checked. Returns an [*EnvelopeError] on mismatch.
+func CheckEnvelope(req, res *protocol.Message, path string) (env Envelope, err error) {
+	env = Envelope{
+		Path:            path,
+		DeliveryChannel: res.GetMessage().GetDeliveryChannel(),
+		Sender:          ParticipantString(res.GetMessage().GetSender()),
+		Recipient:       ParticipantString(res.GetMessage().GetRecipient()),
+		Tail:            res.GetTail().GetValue(),
+	}
+
+	mismatch := func(field string, want, got any) error {
+		return &EnvelopeError{Field: field, Want: fmt.Sprint(want), Got: fmt.Sprint(got), Envelope: env}
+	}
+
+	if want := ParticipantString(req.GetMessage().GetRecipient()); env.Sender != "" && env.Sender != want {
+		return env, mismatch("sender", want, env.Sender)
+	}
+	if want := ParticipantString(req.GetMessage().GetSender()); env.Recipient != "" && env.Recipient != want {
+		return env, mismatch("recipient", want, env.Recipient)
+	}
+	if want := req.GetMessage().GetDeliveryChannel(); env.DeliveryChannel != 0 && env.DeliveryChannel != want {
+		return env, mismatch("channel", want, env.DeliveryChannel)
+	}
+	if want := req.GetTail().GetValue(); env.Tail != 0 && env.Tail != want {
+		return env, mismatch("tail", want, env.Tail)
+	}
+	return env, nil
+}
+
+// exchange sends a message and checks the response's envelope against it.
+func (td *TEDApi) exchange(ctx context.Context, req *protocol.Message, customDin string) (res *protocol.Message, env Envelope, err error) {
+	res = &protocol.Message{}
+	remote, err := td.internalMessagePost(ctx, req, res, customDin)
+	if err != nil {
+		if isAuthFailure(err) && customDin == "" {
+			// we might be talking to a different leader than before
+			td.recheckDIN(ctx, remote, req)
+		}
+		return nil, Envelope{}, err
+	}
+	env, err = CheckEnvelope(req, res, protocol.Path(customDin))
+	env.Remote = remote
+	if isLeaderSwap(err, customDin) {
+		td.forgetDIN(ParticipantString(req.GetMessage().GetRecipient()))
+	}
+	if err != nil {
+		return nil, env, err
+	}
+	return res, env, nil
+}
@@ -0,0 +1,56 @@
+package powerwall
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+func TestCheckEnvelope(t *testing.T) {
+	const other = "1707000-11-J--TG000000000000"
+
+	message := func(channel int32, sender, recipient *protocol.Participant, tail int32) *protocol.Message {
+		m := &protocol.Message{
+			Message: &protocol.MessageEnvelope{DeliveryChannel: channel, Sender: sender, Recipient: recipient},
+		}
+		if tail != 0 {
+			m.Tail = &protocol.Tail{Value: tail}
+		}
+		return m
+	}
+	toLeader := message(1, protocol.LocalParticipant(), protocol.DINParticipant(fakeLeaderDIN), 1)
+	toLocal := message(1, protocol.LocalParticipant(), protocol.LocalParticipant(), 1)
+
+	tests := []struct {
+		name      string
+		req, res  *protocol.Message
+		wantField string // empty if no error
+	}{
+		{"match", toLeader, message(1, protocol.DINParticipant(fakeLeaderDIN), protocol.LocalParticipant(), 1), ""},
+		{"unset fields", toLeader, message(0, protocol.DINParticipant(fakeLeaderDIN), nil, 0), ""},
+		{"missing sender", toLeader, message(1, nil, protocol.LocalParticipant(), 1), ""},
+		{"wrong sender", toLeader, message(1, protocol.DINParticipant(other), protocol.LocalParticipant(), 1), "sender"},
+		{"wrong recipient", toLeader, message(1, protocol.DINParticipant(fakeLeaderDIN), protocol.DINParticipant(other), 1), "recipient"},
+		{"wrong channel", toLeader, message(2, protocol.DINParticipant(fakeLeaderDIN), nil, 1), "channel"},
+		{"wrong tail", toLeader, message(1, protocol.DINParticipant(fakeLeaderDIN), nil, 2), "tail"},
+		{"local without sender", toLocal, message(1, nil, nil, 1), ""},
+		{"local wrong sender", toLocal, message(1, protocol.DINParticipant(other), nil, 1), "sender"},
+	}
+	for _, tt := range tests {
+		env, err := CheckEnvelope(tt.req, tt.res, "/tedapi/v1")
+		if env.Path != "/tedapi/v1" {
+			t.Errorf("%s: got path %q", tt.name, env.Path)
+		}
+		if tt.wantField == "" {
+			if err != nil {
+				t.Errorf("%s: got err %v", tt.name, err)
+			}
+			continue
+		}
+		var envErr *EnvelopeError
+		if !errors.As(err, &envErr) || envErr.Field != tt.wantField || !errors.Is(err, ErrEnvelopeMismatch) {
+			t.Errorf("%s: got %v, want %s mismatch", tt.name, err, tt.wantField)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package powerwall
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// EsCanBus is the "esCan.bus" part of a response, keyed by device type (e.g., "PVAC", "SYNC").
+type EsCanBus map[string]EsCanDevices
+
+// EsCanDevices is the devices of one type on the esCan bus.
+// It decodes from either a single object or an array of them, as the gateway isn't consistent about this (e.g.,
+// "PVAC" is usually an array, "SYNC" is not).
+type EsCanDevices []EsCanDevice
+
+func (d *EsCanDevices) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		*d = nil
+		return nil
+	}
+	if len(b) > 0 && b[0] == '[' {
+		var all []EsCanDevice
+		err := json.Unmarshal(b, &all)
+		*d = all
+		return err
+	}
+
+	var one EsCanDevice
+	err := json.Unmarshal(b, &one)
+	if err != nil {
+		return err
+	}
+	*d = EsCanDevices{one}
+	return nil
+}
+
+// EsCanDevice is a single device on the esCan bus.
+// It has some top-level fields (e.g., "packagePartNumber") and a number of messages (e.g., "SYNC_InfoMsg").
+type EsCanDevice map[string]any
+
+func (d EsCanDevice) Text(key string) string {
+	return anyText(d[key])
+}
+
+func (d EsCanDevice) Message(key string) EsCanMessage {
+	m, _ := d[key].(map[string]any)
+	return m
+}
+
+// EsCanMessage is a single message from a device on the esCan bus.
+type EsCanMessage map[string]any
+
+func (m EsCanMessage) Text(key string) string {
+	return anyText(m[key])
+}
+
+func (m EsCanMessage) Float(key string) (out float64, ok bool) {
+	out, ok = m[key].(float64)
+	return
+}
+
+// anyText formats a decoded JSON string or number, returning "" for anything else.
+func anyText(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return ""
+}
+
+// unavailable returns whether this message's values shouldn't be trusted, as it's missing-in-action or incomplete.
+// A message that's absent entirely is also unavailable.
+func (m EsCanMessage) unavailable() bool {
+	return m == nil || m["isMIA"] == true || m["isComplete"] == false
+}
+
+// measurement returns the named value as a [Measurement], respecting isMIA and isComplete.
+func (m EsCanMessage) measurement(key string) Measurement {
+	v, ok := m.Float(key)
+	if m.unavailable() {
+		return Measurement{Value: v, MIA: m != nil}
+	}
+	return Measurement{Value: v, Valid: ok}
+}
+
+// mia lists the messages on the bus reported as missing-in-action, e.g., "SYNC.METER_X_AcMeasurements".
+func (b EsCanBus) mia() (out []string) {
+	for _, typ := range slices.Sorted(maps.Keys(b)) {
+		devices := b[typ]
+		for i, dev := range devices {
+			prefix := typ
+			if len(devices) > 1 {
+				prefix = fmt.Sprintf("%s[%d]", typ, i)
+			}
+			for _, key := range slices.Sorted(maps.Keys(dev)) {
+				if dev.Message(key)["isMIA"] == true {
+					out = append(out, prefix+"."+key)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// anyMIA returns whether any device on the bus flags one of these messages, e.g., "SYNC.METER_X_AcMeasurements", as
+// missing-in-action.
+func (b EsCanBus) anyMIA(keys ...string) bool {
+	for _, key := range keys {
+		typ, message, _ := strings.Cut(key, ".")
+		for _, dev := range b[typ] {
+			if dev.Message(message)["isMIA"] == true {
+				return true
+			}
+		}
+	}
+	return false
+}
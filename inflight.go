@@ -0,0 +1,37 @@
+package powerwall
+
+import (
+	"context"
+	"time"
+)
+
+// inflight is a call (e.g., a lookup) that's shared by every caller waiting on it.
+type inflight[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// start runs fn in the background; it must be called once, before anyone waits.
+// As the call is shared, it isn't tied to the context of the caller that happened to start it (which may give up while
+// others still wait), so fn gets a context without ctx's cancelation but with its own timeout.
+func (c *inflight[T]) start(ctx context.Context, timeout time.Duration, fn func(ctx context.Context) (T, error)) {
+	c.done = make(chan struct{})
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		c.val, c.err = fn(ctx)
+		close(c.done)
+	}()
+}
+
+// wait returns the result of the call, or gives up when ctx is done.
+func (c *inflight[T]) wait(ctx context.Context) (val T, err error) {
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return val, ctx.Err()
+	}
+}
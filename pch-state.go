@@ -0,0 +1,146 @@
+package powerwall
+
+import (
+	"fmt"
+	"strings"
+)
+
+// These are the enum-like signals reported by a PW3's PCH (power conversion hardware), e.g., "PCH_State".
+// Tesla doesn't document their values, so they're kept as reported: the raw number, plus the gateway's textValue if
+// it sent one. String gives a best-effort label: the textValue if present, otherwise a name from the tables below.
+// The tables are unverified guesses from watching a few systems, so compare Value rather than relying on them.
+// Each type encodes as its label (as text, or a JSON string), like "Active", "PCHState(7)" or "Unknown".
+
+// EnumSignal is the raw value of an enum-like signal.
+// The zero value is "Unknown": the signal wasn't reported.
+type EnumSignal struct {
+	Valid bool   `json:"valid"`         // whether the signal was reported
+	Value int    `json:"value"`         // raw number, meaningless if !Valid
+	Text  string `json:"text,omitzero"` // textValue, if the gateway sent one
+}
+
+// enumSignal reads the named signal, keeping both its number and textValue.
+func (ra Signals) enumSignal(name string) (e EnumSignal) {
+	for _, s := range ra {
+		if s.Name != name {
+			continue
+		}
+		if s.Value != nil {
+			e.Valid = true
+			e.Value = int(*s.Value)
+		}
+		if s.TextValue != nil && *s.TextValue != "" {
+			e.Valid = true
+			e.Text = *s.TextValue
+		}
+		break
+	}
+	return e
+}
+
+func (e EnumSignal) label(names map[int]string, kind string) string {
+	if !e.Valid {
+		return "Unknown"
+	} else if e.Text != "" {
+		return e.Text
+	} else if name, ok := names[e.Value]; ok {
+		return name
+	}
+	return fmt.Sprintf("%s(%d)", kind, e.Value)
+}
+
+// parseLabel is the reverse of label: a known name or "Kind(n)" gives the value, and any other label is kept as Text.
+func (e *EnumSignal) parseLabel(names map[int]string, kind, s string) error {
+	*e = EnumSignal{}
+	if s == "Unknown" || s == "" {
+		return nil
+	}
+	e.Valid = true
+	for value, name := range names {
+		if name == s {
+			e.Value = value
+			return nil
+		}
+	}
+	if strings.HasPrefix(s, kind+"(") {
+		if _, err := fmt.Sscanf(s[len(kind):], "(%d)", &e.Value); err == nil {
+			return nil
+		}
+	}
+	e.Text = s
+	return nil
+}
+
+// PCHState is the overall inverter state from "PCH_State".
+type PCHState struct{ EnumSignal }
+
+var pchStateNames = map[int]string{
+	0: "Init",
+	1: "Idle",
+	2: "Standby",
+	3: "Active",
+	4: "Fault",
+	5: "Shutdown",
+}
+
+func (s PCHState) String() string { return s.label(pchStateNames, "PCHState") }
+
+func (s PCHState) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *PCHState) UnmarshalText(b []byte) error {
+	return s.parseLabel(pchStateNames, "PCHState", string(b))
+}
+
+// PVState is the state of a single MPPT from "PCH_PvState_A" through "PCH_PvState_F".
+type PVState struct{ EnumSignal }
+
+var pvStateNames = map[int]string{
+	0: "Disabled",
+	1: "Standby",
+	2: "Active",
+	3: "ActiveParallel",
+	4: "Fault",
+}
+
+func (s PVState) String() string { return s.label(pvStateNames, "PVState") }
+
+func (s PVState) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *PVState) UnmarshalText(b []byte) error {
+	return s.parseLabel(pvStateNames, "PVState", string(b))
+}
+
+// ACMode is the grid-facing mode of the inverter from "PCH_AcMode".
+type ACMode struct{ EnumSignal }
+
+var acModeNames = map[int]string{
+	0: "Disconnected",
+	1: "GridFollowing",
+	2: "GridForming",
+}
+
+func (m ACMode) String() string { return m.label(acModeNames, "ACMode") }
+
+func (m ACMode) MarshalText() ([]byte, error) { return []byte(m.String()), nil }
+
+func (m *ACMode) UnmarshalText(b []byte) error {
+	return m.parseLabel(acModeNames, "ACMode", string(b))
+}
+
+// DCDCState is the state of a battery-side DC/DC converter from "PCH_DcdcState_A" or "PCH_DcdcState_B".
+type DCDCState struct{ EnumSignal }
+
+var dcdcStateNames = map[int]string{
+	0: "Off",
+	1: "Standby",
+	2: "Active",
+	3: "Fault",
+}
+
+func (s DCDCState) String() string { return s.label(dcdcStateNames, "DCDCState") }
+
+func (s DCDCState) MarshalText() ([]byte, error) { return []byte(s.String()), nil }
+
+func (s *DCDCState) UnmarshalText(b []byte) error {
+	return s.parseLabel(dcdcStateNames, "DCDCState", string(b))
+}
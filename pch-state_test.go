@@ -0,0 +1,84 @@
+package powerwall
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEnumSignalString(t *testing.T) {
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"missing", PCHState{}.String(), "Unknown"},
+		{"zero is not missing", PCHState{EnumSignal{Valid: true}}.String(), "Init"},
+		{"known", PCHState{EnumSignal{Valid: true, Value: 3}}.String(), "Active"},
+		{"unknown value", PCHState{EnumSignal{Valid: true, Value: 42}}.String(), "PCHState(42)"},
+		{"text preferred", PCHState{EnumSignal{Valid: true, Value: 3, Text: "PCH_STATE_RUN"}}.String(), "PCH_STATE_RUN"},
+		{"pv", PVState{EnumSignal{Valid: true, Value: 3}}.String(), "ActiveParallel"},
+		{"ac", ACMode{EnumSignal{Valid: true, Value: 2}}.String(), "GridForming"},
+		{"dcdc", DCDCState{EnumSignal{Valid: true, Value: 9}}.String(), "DCDCState(9)"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestDecodePCHEnums(t *testing.T) {
+	tests := []struct {
+		name    string
+		signals Signals
+		want    EnumSignal
+	}{
+		{"missing", Signals{{Name: "PCH_AcFrequency", Value: ptrTo(60.0)}}, EnumSignal{}},
+		{"zero", Signals{{Name: "PCH_State", Value: ptrTo(0.0)}}, EnumSignal{Valid: true}},
+		{"value", Signals{{Name: "PCH_State", Value: ptrTo(3.0)}}, EnumSignal{Valid: true, Value: 3}},
+		{"text only", Signals{{Name: "PCH_State", TextValue: ptrTo("Run")}}, EnumSignal{Valid: true, Text: "Run"}},
+		{"both", Signals{{Name: "PCH_State", Value: ptrTo(3.0), TextValue: ptrTo("Run")}}, EnumSignal{Valid: true, Value: 3, Text: "Run"}},
+		{"empty text", Signals{{Name: "PCH_State", TextValue: ptrTo("")}}, EnumSignal{}},
+	}
+	for _, tt := range tests {
+		got := decodePCH(tt.signals).State.EnumSignal
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestEnumSignalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		in   PCHState
+		want string
+	}{
+		{"missing", PCHState{}, `"Unknown"`},
+		{"known", PCHState{EnumSignal{Valid: true, Value: 3}}, `"Active"`},
+		{"zero", PCHState{EnumSignal{Valid: true}}, `"Init"`},
+		{"unknown value", PCHState{EnumSignal{Valid: true, Value: 42}}, `"PCHState(42)"`},
+		{"text", PCHState{EnumSignal{Valid: true, Text: "Run"}}, `"Run"`},
+	}
+	for _, tt := range tests {
+		b, err := json.Marshal(tt.in)
+		if err != nil {
+			t.Fatal(err)
+		} else if string(b) != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.name, b, tt.want)
+		}
+
+		var back PCHState
+		if err := json.Unmarshal(b, &back); err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+		} else if back != tt.in {
+			t.Errorf("%s: round trip got %+v, want %+v", tt.name, back, tt.in)
+		}
+	}
+
+	// embedded in a status, each enum is a scalar
+	b, _ := json.Marshal(SimpleDeviceMPPTStatus{State: PVState{EnumSignal{Valid: true, Value: 2}}})
+	if string(b) != `{"c":0,"v":0,"state":"Active"}` {
+		t.Errorf("got %s", b)
+	}
+}
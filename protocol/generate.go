@@ -0,0 +1,4 @@
+package protocol
+
+// tedapi.pb.go is generated from tedapi.proto (from pypowerwall's tools/tedapi) with protoc and protoc-gen-go v1.36.8.
+//go:generate protoc --go_out=. --go_opt=paths=source_relative tedapi.proto
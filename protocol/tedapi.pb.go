@@ -7,21 +7,22 @@
 //
 // For more information see https://github.com/jasonacox/pypowerwall
 
+// This copy adds go_package for the protocol package; regenerate tedapi.pb.go with "go generate" (see generate.go).
+
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.8
-// 	protoc        v6.32.0
-// source: tools/tedapi/tedapi.proto
+// 	protoc        (unknown)
+// source: tedapi.proto
 
-package internal
+package protocol
 
 import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
-
-	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
-	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 )
 
 const (
@@ -41,7 +42,7 @@ type Message struct {
 
 func (x *Message) Reset() {
 	*x = Message{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[0]
+	mi := &file_tedapi_proto_msgTypes[0]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -53,7 +54,7 @@ func (x *Message) String() string {
 func (*Message) ProtoMessage() {}
 
 func (x *Message) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[0]
+	mi := &file_tedapi_proto_msgTypes[0]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -66,7 +67,7 @@ func (x *Message) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Message.ProtoReflect.Descriptor instead.
 func (*Message) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{0}
+	return file_tedapi_proto_rawDescGZIP(), []int{0}
 }
 
 func (x *Message) GetMessage() *MessageEnvelope {
@@ -97,7 +98,7 @@ type MessageEnvelope struct {
 
 func (x *MessageEnvelope) Reset() {
 	*x = MessageEnvelope{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[1]
+	mi := &file_tedapi_proto_msgTypes[1]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -109,7 +110,7 @@ func (x *MessageEnvelope) String() string {
 func (*MessageEnvelope) ProtoMessage() {}
 
 func (x *MessageEnvelope) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[1]
+	mi := &file_tedapi_proto_msgTypes[1]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -122,7 +123,7 @@ func (x *MessageEnvelope) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MessageEnvelope.ProtoReflect.Descriptor instead.
 func (*MessageEnvelope) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{1}
+	return file_tedapi_proto_rawDescGZIP(), []int{1}
 }
 
 func (x *MessageEnvelope) GetDeliveryChannel() int32 {
@@ -182,7 +183,7 @@ type Participant struct {
 
 func (x *Participant) Reset() {
 	*x = Participant{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[2]
+	mi := &file_tedapi_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -194,7 +195,7 @@ func (x *Participant) String() string {
 func (*Participant) ProtoMessage() {}
 
 func (x *Participant) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[2]
+	mi := &file_tedapi_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,7 +208,7 @@ func (x *Participant) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Participant.ProtoReflect.Descriptor instead.
 func (*Participant) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{2}
+	return file_tedapi_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Participant) GetId() isParticipant_Id {
@@ -290,7 +291,7 @@ type Tail struct {
 
 func (x *Tail) Reset() {
 	*x = Tail{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[3]
+	mi := &file_tedapi_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -302,7 +303,7 @@ func (x *Tail) String() string {
 func (*Tail) ProtoMessage() {}
 
 func (x *Tail) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[3]
+	mi := &file_tedapi_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -315,7 +316,7 @@ func (x *Tail) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tail.ProtoReflect.Descriptor instead.
 func (*Tail) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{3}
+	return file_tedapi_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Tail) GetValue() int32 {
@@ -338,7 +339,7 @@ type FirmwareType struct {
 
 func (x *FirmwareType) Reset() {
 	*x = FirmwareType{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[4]
+	mi := &file_tedapi_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -350,7 +351,7 @@ func (x *FirmwareType) String() string {
 func (*FirmwareType) ProtoMessage() {}
 
 func (x *FirmwareType) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[4]
+	mi := &file_tedapi_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -363,7 +364,7 @@ func (x *FirmwareType) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FirmwareType.ProtoReflect.Descriptor instead.
 func (*FirmwareType) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{4}
+	return file_tedapi_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *FirmwareType) GetId() isFirmwareType_Id {
@@ -423,7 +424,7 @@ type FirmwarePayload struct {
 
 func (x *FirmwarePayload) Reset() {
 	*x = FirmwarePayload{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[5]
+	mi := &file_tedapi_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -435,7 +436,7 @@ func (x *FirmwarePayload) String() string {
 func (*FirmwarePayload) ProtoMessage() {}
 
 func (x *FirmwarePayload) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[5]
+	mi := &file_tedapi_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -448,7 +449,7 @@ func (x *FirmwarePayload) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FirmwarePayload.ProtoReflect.Descriptor instead.
 func (*FirmwarePayload) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{5}
+	return file_tedapi_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *FirmwarePayload) GetGateway() *EcuId {
@@ -517,7 +518,7 @@ type EcuId struct {
 
 func (x *EcuId) Reset() {
 	*x = EcuId{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[6]
+	mi := &file_tedapi_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -529,7 +530,7 @@ func (x *EcuId) String() string {
 func (*EcuId) ProtoMessage() {}
 
 func (x *EcuId) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[6]
+	mi := &file_tedapi_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -542,7 +543,7 @@ func (x *EcuId) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use EcuId.ProtoReflect.Descriptor instead.
 func (*EcuId) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{6}
+	return file_tedapi_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *EcuId) GetPartNumber() string {
@@ -569,7 +570,7 @@ type FirmwareVersion struct {
 
 func (x *FirmwareVersion) Reset() {
 	*x = FirmwareVersion{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[7]
+	mi := &file_tedapi_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -581,7 +582,7 @@ func (x *FirmwareVersion) String() string {
 func (*FirmwareVersion) ProtoMessage() {}
 
 func (x *FirmwareVersion) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[7]
+	mi := &file_tedapi_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -594,7 +595,7 @@ func (x *FirmwareVersion) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FirmwareVersion.ProtoReflect.Descriptor instead.
 func (*FirmwareVersion) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{7}
+	return file_tedapi_proto_rawDescGZIP(), []int{7}
 }
 
 func (x *FirmwareVersion) GetText() string {
@@ -620,7 +621,7 @@ type FirmwareFive struct {
 
 func (x *FirmwareFive) Reset() {
 	*x = FirmwareFive{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[8]
+	mi := &file_tedapi_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -632,7 +633,7 @@ func (x *FirmwareFive) String() string {
 func (*FirmwareFive) ProtoMessage() {}
 
 func (x *FirmwareFive) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[8]
+	mi := &file_tedapi_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -645,7 +646,7 @@ func (x *FirmwareFive) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use FirmwareFive.ProtoReflect.Descriptor instead.
 func (*FirmwareFive) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{8}
+	return file_tedapi_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *FirmwareFive) GetD() int32 {
@@ -664,7 +665,7 @@ type DeviceArray struct {
 
 func (x *DeviceArray) Reset() {
 	*x = DeviceArray{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[9]
+	mi := &file_tedapi_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -676,7 +677,7 @@ func (x *DeviceArray) String() string {
 func (*DeviceArray) ProtoMessage() {}
 
 func (x *DeviceArray) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[9]
+	mi := &file_tedapi_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -689,7 +690,7 @@ func (x *DeviceArray) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeviceArray.ProtoReflect.Descriptor instead.
 func (*DeviceArray) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{9}
+	return file_tedapi_proto_rawDescGZIP(), []int{9}
 }
 
 func (x *DeviceArray) GetDevice() []*DeviceInfo {
@@ -711,7 +712,7 @@ type DeviceInfo struct {
 
 func (x *DeviceInfo) Reset() {
 	*x = DeviceInfo{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[10]
+	mi := &file_tedapi_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -723,7 +724,7 @@ func (x *DeviceInfo) String() string {
 func (*DeviceInfo) ProtoMessage() {}
 
 func (x *DeviceInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[10]
+	mi := &file_tedapi_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -736,7 +737,7 @@ func (x *DeviceInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use DeviceInfo.ProtoReflect.Descriptor instead.
 func (*DeviceInfo) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{10}
+	return file_tedapi_proto_rawDescGZIP(), []int{10}
 }
 
 func (x *DeviceInfo) GetCompany() *StringValue {
@@ -777,7 +778,7 @@ type QueryType struct {
 
 func (x *QueryType) Reset() {
 	*x = QueryType{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[11]
+	mi := &file_tedapi_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -789,7 +790,7 @@ func (x *QueryType) String() string {
 func (*QueryType) ProtoMessage() {}
 
 func (x *QueryType) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[11]
+	mi := &file_tedapi_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -802,7 +803,7 @@ func (x *QueryType) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use QueryType.ProtoReflect.Descriptor instead.
 func (*QueryType) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{11}
+	return file_tedapi_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *QueryType) GetSend() *PayloadQuerySend {
@@ -831,7 +832,7 @@ type PayloadQuerySend struct {
 
 func (x *PayloadQuerySend) Reset() {
 	*x = PayloadQuerySend{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[12]
+	mi := &file_tedapi_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -843,7 +844,7 @@ func (x *PayloadQuerySend) String() string {
 func (*PayloadQuerySend) ProtoMessage() {}
 
 func (x *PayloadQuerySend) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[12]
+	mi := &file_tedapi_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -856,7 +857,7 @@ func (x *PayloadQuerySend) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PayloadQuerySend.ProtoReflect.Descriptor instead.
 func (*PayloadQuerySend) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{12}
+	return file_tedapi_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *PayloadQuerySend) GetNum() int32 {
@@ -900,7 +901,7 @@ type ConfigType struct {
 
 func (x *ConfigType) Reset() {
 	*x = ConfigType{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[13]
+	mi := &file_tedapi_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -912,7 +913,7 @@ func (x *ConfigType) String() string {
 func (*ConfigType) ProtoMessage() {}
 
 func (x *ConfigType) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[13]
+	mi := &file_tedapi_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -925,7 +926,7 @@ func (x *ConfigType) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigType.ProtoReflect.Descriptor instead.
 func (*ConfigType) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{13}
+	return file_tedapi_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *ConfigType) GetConfig() isConfigType_Config {
@@ -979,7 +980,7 @@ type PayloadConfigSend struct {
 
 func (x *PayloadConfigSend) Reset() {
 	*x = PayloadConfigSend{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[14]
+	mi := &file_tedapi_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -991,7 +992,7 @@ func (x *PayloadConfigSend) String() string {
 func (*PayloadConfigSend) ProtoMessage() {}
 
 func (x *PayloadConfigSend) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[14]
+	mi := &file_tedapi_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1004,7 +1005,7 @@ func (x *PayloadConfigSend) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PayloadConfigSend.ProtoReflect.Descriptor instead.
 func (*PayloadConfigSend) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{14}
+	return file_tedapi_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *PayloadConfigSend) GetNum() int32 {
@@ -1031,7 +1032,7 @@ type PayloadConfigRecv struct {
 
 func (x *PayloadConfigRecv) Reset() {
 	*x = PayloadConfigRecv{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[15]
+	mi := &file_tedapi_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1043,7 +1044,7 @@ func (x *PayloadConfigRecv) String() string {
 func (*PayloadConfigRecv) ProtoMessage() {}
 
 func (x *PayloadConfigRecv) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[15]
+	mi := &file_tedapi_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1056,7 +1057,7 @@ func (x *PayloadConfigRecv) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PayloadConfigRecv.ProtoReflect.Descriptor instead.
 func (*PayloadConfigRecv) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{15}
+	return file_tedapi_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *PayloadConfigRecv) GetFile() *ConfigString {
@@ -1083,7 +1084,7 @@ type ConfigString struct {
 
 func (x *ConfigString) Reset() {
 	*x = ConfigString{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[16]
+	mi := &file_tedapi_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1095,7 +1096,7 @@ func (x *ConfigString) String() string {
 func (*ConfigString) ProtoMessage() {}
 
 func (x *ConfigString) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[16]
+	mi := &file_tedapi_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1108,7 +1109,7 @@ func (x *ConfigString) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConfigString.ProtoReflect.Descriptor instead.
 func (*ConfigString) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{16}
+	return file_tedapi_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *ConfigString) GetName() string {
@@ -1135,7 +1136,7 @@ type PayloadString struct {
 
 func (x *PayloadString) Reset() {
 	*x = PayloadString{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[17]
+	mi := &file_tedapi_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1147,7 +1148,7 @@ func (x *PayloadString) String() string {
 func (*PayloadString) ProtoMessage() {}
 
 func (x *PayloadString) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[17]
+	mi := &file_tedapi_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1160,7 +1161,7 @@ func (x *PayloadString) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use PayloadString.ProtoReflect.Descriptor instead.
 func (*PayloadString) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{17}
+	return file_tedapi_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *PayloadString) GetValue() int32 {
@@ -1186,7 +1187,7 @@ type StringValue struct {
 
 func (x *StringValue) Reset() {
 	*x = StringValue{}
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[18]
+	mi := &file_tedapi_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1198,7 +1199,7 @@ func (x *StringValue) String() string {
 func (*StringValue) ProtoMessage() {}
 
 func (x *StringValue) ProtoReflect() protoreflect.Message {
-	mi := &file_tools_tedapi_tedapi_proto_msgTypes[18]
+	mi := &file_tedapi_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1211,7 +1212,7 @@ func (x *StringValue) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StringValue.ProtoReflect.Descriptor instead.
 func (*StringValue) Descriptor() ([]byte, []int) {
-	return file_tools_tedapi_tedapi_proto_rawDescGZIP(), []int{18}
+	return file_tedapi_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *StringValue) GetValue() string {
@@ -1221,11 +1222,11 @@ func (x *StringValue) GetValue() string {
 	return ""
 }
 
-var File_tools_tedapi_tedapi_proto protoreflect.FileDescriptor
+var File_tedapi_proto protoreflect.FileDescriptor
 
-const file_tools_tedapi_tedapi_proto_rawDesc = "" +
+const file_tedapi_proto_rawDesc = "" +
 	"\n" +
-	"\x19tools/tedapi/tedapi.proto\x12\x06tedapi\"^\n" +
+	"\ftedapi.proto\x12\x06tedapi\"^\n" +
 	"\aMessage\x121\n" +
 	"\amessage\x18\x01 \x01(\v2\x17.tedapi.MessageEnvelopeR\amessage\x12 \n" +
 	"\x04tail\x18\x02 \x01(\v2\f.tedapi.TailR\x04tail\"\xc7\x02\n" +
@@ -1311,22 +1312,22 @@ const file_tools_tedapi_tedapi_proto_rawDesc = "" +
 	"\x05value\x18\x01 \x01(\x05R\x05value\x12\x12\n" +
 	"\x04text\x18\x02 \x01(\tR\x04text\"#\n" +
 	"\vStringValue\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\tR\x05valueb\x06proto3"
+	"\x05value\x18\x01 \x01(\tR\x05valueB'Z%github.com/samthor/powerwall/protocolb\x06proto3"
 
 var (
-	file_tools_tedapi_tedapi_proto_rawDescOnce sync.Once
-	file_tools_tedapi_tedapi_proto_rawDescData []byte
+	file_tedapi_proto_rawDescOnce sync.Once
+	file_tedapi_proto_rawDescData []byte
 )
 
-func file_tools_tedapi_tedapi_proto_rawDescGZIP() []byte {
-	file_tools_tedapi_tedapi_proto_rawDescOnce.Do(func() {
-		file_tools_tedapi_tedapi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tools_tedapi_tedapi_proto_rawDesc), len(file_tools_tedapi_tedapi_proto_rawDesc)))
+func file_tedapi_proto_rawDescGZIP() []byte {
+	file_tedapi_proto_rawDescOnce.Do(func() {
+		file_tedapi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_tedapi_proto_rawDesc), len(file_tedapi_proto_rawDesc)))
 	})
-	return file_tools_tedapi_tedapi_proto_rawDescData
+	return file_tedapi_proto_rawDescData
 }
 
-var file_tools_tedapi_tedapi_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
-var file_tools_tedapi_tedapi_proto_goTypes = []any{
+var file_tedapi_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_tedapi_proto_goTypes = []any{
 	(*Message)(nil),           // 0: tedapi.Message
 	(*MessageEnvelope)(nil),   // 1: tedapi.MessageEnvelope
 	(*Participant)(nil),       // 2: tedapi.Participant
@@ -1347,7 +1348,7 @@ var file_tools_tedapi_tedapi_proto_goTypes = []any{
 	(*PayloadString)(nil),     // 17: tedapi.PayloadString
 	(*StringValue)(nil),       // 18: tedapi.StringValue
 }
-var file_tools_tedapi_tedapi_proto_depIdxs = []int32{
+var file_tedapi_proto_depIdxs = []int32{
 	1,  // 0: tedapi.Message.message:type_name -> tedapi.MessageEnvelope
 	3,  // 1: tedapi.Message.tail:type_name -> tedapi.Tail
 	2,  // 2: tedapi.MessageEnvelope.sender:type_name -> tedapi.Participant
@@ -1379,25 +1380,25 @@ var file_tools_tedapi_tedapi_proto_depIdxs = []int32{
 	0,  // [0:24] is the sub-list for field type_name
 }
 
-func init() { file_tools_tedapi_tedapi_proto_init() }
-func file_tools_tedapi_tedapi_proto_init() {
-	if File_tools_tedapi_tedapi_proto != nil {
+func init() { file_tedapi_proto_init() }
+func file_tedapi_proto_init() {
+	if File_tedapi_proto != nil {
 		return
 	}
-	file_tools_tedapi_tedapi_proto_msgTypes[1].OneofWrappers = []any{}
-	file_tools_tedapi_tedapi_proto_msgTypes[2].OneofWrappers = []any{
+	file_tedapi_proto_msgTypes[1].OneofWrappers = []any{}
+	file_tedapi_proto_msgTypes[2].OneofWrappers = []any{
 		(*Participant_Din)(nil),
 		(*Participant_TeslaService)(nil),
 		(*Participant_Local)(nil),
 		(*Participant_AuthorizedClient)(nil),
 	}
-	file_tools_tedapi_tedapi_proto_msgTypes[4].OneofWrappers = []any{
+	file_tedapi_proto_msgTypes[4].OneofWrappers = []any{
 		(*FirmwareType_Request)(nil),
 		(*FirmwareType_System)(nil),
 	}
-	file_tools_tedapi_tedapi_proto_msgTypes[11].OneofWrappers = []any{}
-	file_tools_tedapi_tedapi_proto_msgTypes[12].OneofWrappers = []any{}
-	file_tools_tedapi_tedapi_proto_msgTypes[13].OneofWrappers = []any{
+	file_tedapi_proto_msgTypes[11].OneofWrappers = []any{}
+	file_tedapi_proto_msgTypes[12].OneofWrappers = []any{}
+	file_tedapi_proto_msgTypes[13].OneofWrappers = []any{
 		(*ConfigType_Send)(nil),
 		(*ConfigType_Recv)(nil),
 	}
@@ -1405,17 +1406,17 @@ func file_tools_tedapi_tedapi_proto_init() {
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
-			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tools_tedapi_tedapi_proto_rawDesc), len(file_tools_tedapi_tedapi_proto_rawDesc)),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_tedapi_proto_rawDesc), len(file_tedapi_proto_rawDesc)),
 			NumEnums:      0,
 			NumMessages:   19,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
-		GoTypes:           file_tools_tedapi_tedapi_proto_goTypes,
-		DependencyIndexes: file_tools_tedapi_tedapi_proto_depIdxs,
-		MessageInfos:      file_tools_tedapi_tedapi_proto_msgTypes,
+		GoTypes:           file_tedapi_proto_goTypes,
+		DependencyIndexes: file_tedapi_proto_depIdxs,
+		MessageInfos:      file_tedapi_proto_msgTypes,
 	}.Build()
-	File_tools_tedapi_tedapi_proto = out.File
-	file_tools_tedapi_tedapi_proto_goTypes = nil
-	file_tools_tedapi_tedapi_proto_depIdxs = nil
+	File_tedapi_proto = out.File
+	file_tedapi_proto_goTypes = nil
+	file_tedapi_proto_depIdxs = nil
 }
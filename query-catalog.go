@@ -0,0 +1,94 @@
+package powerwall
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// QueryCatalog is a set of signed queries loaded at runtime, e.g., from a JSON file.
+//
+//	{"queries": [{"name": "...", "query": "...", "signature": "<base64>", "vars": {...}, "target": "leader"}]}
+type QueryCatalog struct {
+	Queries []CatalogQuery `json:"queries"`
+}
+
+// CatalogQuery is a single entry in a [QueryCatalog].
+type CatalogQuery struct {
+	Name      string          `json:"name"`
+	Query     string          `json:"query"`           // GraphQL text, exactly as signed
+	Signature []byte          `json:"signature"`       // base64 in JSON
+	Vars      json.RawMessage `json:"vars,omitzero"`   // default variables
+	Target    QueryTarget     `json:"target,omitzero"` // "leader" (default) or "device"
+}
+
+// ToQuery returns this entry as a [Query].
+func (cq CatalogQuery) ToQuery() Query {
+	return Query{Query: cq.Query, Signature: cq.Signature, Vars: cq.Vars}
+}
+
+// LoadQueryCatalog reads and validates a JSON [QueryCatalog].
+func LoadQueryCatalog(r io.Reader) (c *QueryCatalog, err error) {
+	c = &QueryCatalog{}
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	err = dec.Decode(c)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode query catalog: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for i, cq := range c.Queries {
+		switch {
+		case cq.Name == "":
+			return nil, fmt.Errorf("query catalog entry %d has no name", i)
+		case seen[cq.Name]:
+			return nil, fmt.Errorf("query catalog has duplicate name %q", cq.Name)
+		case cq.Query == "":
+			return nil, fmt.Errorf("query %q has no query text", cq.Name)
+		case len(cq.Signature) == 0:
+			return nil, fmt.Errorf("query %q has no signature", cq.Name)
+		}
+		if cq.Vars != nil {
+			var vars map[string]any
+			if err := json.Unmarshal(cq.Vars, &vars); err != nil {
+				return nil, fmt.Errorf("query %q vars must be an object: %w", cq.Name, err)
+			}
+		}
+		seen[cq.Name] = true
+	}
+
+	return c, nil
+}
+
+// LoadQueryCatalogFile is like [LoadQueryCatalog], but reads from the named file.
+func LoadQueryCatalogFile(path string) (c *QueryCatalog, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadQueryCatalog(f)
+}
+
+// Get returns the named query from the catalog.
+func (c *QueryCatalog) Get(name string) (q Query, ok bool) {
+	for _, cq := range c.Queries {
+		if cq.Name == name {
+			return cq.ToQuery(), true
+		}
+	}
+	return Query{}, false
+}
+
+// Register adds every query in the catalog to the registry (see [RegisterQuery]).
+// It's all or nothing: if any query can't be registered, none are.
+// These have no fixed response type, so [QueryInto] will decode them into any type.
+func (c *QueryCatalog) Register() error {
+	specs := make([]QuerySpec, 0, len(c.Queries))
+	for _, cq := range c.Queries {
+		specs = append(specs, QuerySpec{Name: cq.Name, Query: cq.ToQuery(), Target: cq.Target})
+	}
+	return registerQueries(specs...)
+}
@@ -0,0 +1,95 @@
+package powerwall
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testCatalog = `{"queries": [
+	{"name": "a", "query": "query A { x }", "signature": "Y2F0YWxvZy1zaWctYQ==", "vars": {"n": 1}},
+	{"name": "b", "query": "query B { x }", "signature": "Y2F0YWxvZy1zaWctYg==", "target": "device"}
+]}`
+
+func TestLoadQueryCatalog(t *testing.T) {
+	c, err := LoadQueryCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Queries) != 2 || c.Queries[0].Target != TargetLeader || c.Queries[1].Target != TargetDevice {
+		t.Fatalf("got %+v", c.Queries)
+	}
+	q, ok := c.Get("a")
+	if !ok || string(q.Signature) != "catalog-sig-a" || string(q.Vars) != `{"n": 1}` {
+		t.Errorf("got %+v, %v", q, ok)
+	}
+	if _, ok := c.Get("missing"); ok {
+		t.Errorf("got missing query")
+	}
+
+	tests := []struct {
+		name string
+		json string
+		want string // in the error
+	}{
+		{"not JSON", `{`, "could not decode"},
+		{"unknown field", `{"queries": [], "extra": 1}`, "unknown field"},
+		{"bad target", `{"queries": [{"name": "a", "query": "q", "signature": "c2ln", "target": "all"}]}`, "unknown query target"},
+		{"bad signature", `{"queries": [{"name": "a", "query": "q", "signature": "!"}]}`, "could not decode"},
+		{"no name", `{"queries": [{"query": "q", "signature": "c2ln"}]}`, "entry 0 has no name"},
+		{"duplicate name", `{"queries": [{"name": "a", "query": "q", "signature": "c2ln"}, {"name": "a", "query": "q", "signature": "c2ln"}]}`, "duplicate name"},
+		{"no query", `{"queries": [{"name": "a", "signature": "c2ln"}]}`, "no query text"},
+		{"no signature", `{"queries": [{"name": "a", "query": "q"}]}`, "no signature"},
+		{"vars not object", `{"queries": [{"name": "a", "query": "q", "signature": "c2ln", "vars": [1]}]}`, "must be an object"},
+	}
+	for _, tt := range tests {
+		_, err := LoadQueryCatalog(strings.NewReader(tt.json))
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: got %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestLoadQueryCatalogFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	if err := os.WriteFile(path, []byte(testCatalog), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if c, err := LoadQueryCatalogFile(path); err != nil || len(c.Queries) != 2 {
+		t.Errorf("got %v, %v", c, err)
+	}
+	if _, err := LoadQueryCatalogFile(filepath.Join(t.TempDir(), "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("got %v, want not exist", err)
+	}
+}
+
+func TestQueryCatalogRegister(t *testing.T) {
+	c, err := LoadQueryCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, _ := c.Get("a")
+	b, _ := c.Get("b")
+
+	// b is already registered, so a must not be either
+	if err := registerForTest(t, QuerySpec{Name: "existing", Query: b}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Register(); err == nil {
+		t.Fatalf("expected duplicate to fail")
+	}
+	if spec, ok := LookupQuery(a); ok {
+		t.Errorf("partially registered %+v", spec)
+	}
+
+	// two entries with the same signature are also rejected
+	same := &QueryCatalog{Queries: []CatalogQuery{c.Queries[0], c.Queries[0]}}
+	same.Queries[1].Name = "copy"
+	if err := same.Register(); err == nil {
+		t.Errorf("expected same signature to fail")
+	}
+	if _, ok := LookupQuery(a); ok {
+		t.Errorf("partially registered a")
+	}
+}
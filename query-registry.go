@@ -0,0 +1,184 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// QueryTarget is where a [Query] can be sent.
+type QueryTarget int
+
+const (
+	TargetLeader QueryTarget = iota // only the leader; no DIN may be given
+	TargetDevice                    // the leader, or routed to any device by DIN
+)
+
+func (t QueryTarget) String() string {
+	switch t {
+	case TargetLeader:
+		return "leader"
+	case TargetDevice:
+		return "device"
+	}
+	return fmt.Sprintf("QueryTarget(%d)", int(t))
+}
+
+func (t QueryTarget) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+func (t *QueryTarget) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "leader":
+		*t = TargetLeader
+	case "device":
+		*t = TargetDevice
+	default:
+		return fmt.Errorf("unknown query target: %q", string(b))
+	}
+	return nil
+}
+
+// QuerySpec binds a [Query] to where it's sent and what it decodes into.
+type QuerySpec struct {
+	Name     string
+	Query    Query
+	Target   QueryTarget
+	Type     reflect.Type  // usual response type, for reference; [QueryInto] can decode into any type
+	CacheFor time.Duration // if non-zero, results are cached per-[TEDApi] and device
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]QuerySpec{} // by signature
+)
+
+func init() {
+	MustRegisterQuery(QuerySpec{Name: "status", Query: QueryStatus, Target: TargetLeader, Type: reflect.TypeFor[StatusResponse]()})
+	MustRegisterQuery(QuerySpec{Name: "components", Query: QueryComponents, Target: TargetDevice, Type: reflect.TypeFor[ComponentsResponse]()})
+	MustRegisterQuery(QuerySpec{Name: "deviceController", Query: QueryDeviceController, Target: TargetLeader, Type: reflect.TypeFor[DeviceControllerResponse]()})
+}
+
+// RegisterQuery adds a query to the registry, so [QueryInto] validates and caches it.
+// Queries are identified by their signature, so each signature can only be registered once.
+func RegisterQuery(spec QuerySpec) error {
+	return registerQueries(spec)
+}
+
+// registerQueries adds every spec to the registry, or none of them if any is unsigned or already registered.
+func registerQueries(specs ...QuerySpec) error {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	pending := map[string]string{} // signature to name, for specs before this one
+	for _, spec := range specs {
+		if len(spec.Query.Signature) == 0 {
+			return fmt.Errorf("query %q has no signature", spec.Name)
+		}
+		key := string(spec.Query.Signature)
+		if prev, ok := registry[key]; ok {
+			return fmt.Errorf("query %q already registered as %q", spec.Name, prev.Name)
+		} else if prev, ok := pending[key]; ok {
+			return fmt.Errorf("query %q has the same signature as %q", spec.Name, prev)
+		}
+		pending[key] = spec.Name
+	}
+
+	for _, spec := range specs {
+		registry[string(spec.Query.Signature)] = spec
+	}
+	return nil
+}
+
+// MustRegisterQuery is like [RegisterQuery], but panics on error.
+func MustRegisterQuery(spec QuerySpec) {
+	if err := RegisterQuery(spec); err != nil {
+		panic(err)
+	}
+}
+
+// LookupQuery returns the registered [QuerySpec] for this query, if any.
+func LookupQuery(q Query) (spec QuerySpec, ok bool) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	spec, ok = registry[string(q.Signature)]
+	return spec, ok
+}
+
+// QueryInto performs a query and decodes its result into a new T.
+// Pass a blank din to target the leader.
+// If the query is registered, its target is checked first, and results may be cached.
+// Cached results are kept as raw JSON and decoded afresh for each call, so callers may use different types.
+func QueryInto[T any](ctx context.Context, td *TEDApi, q Query, din string) (out *T, err error) {
+	spec, registered := LookupQuery(q)
+	if registered && spec.Target == TargetLeader && din != "" {
+		return nil, fmt.Errorf("query %q can only target the leader", spec.Name)
+	}
+
+	var raw json.RawMessage
+	var key string
+	if registered && spec.CacheFor > 0 {
+		key, err = queryCacheKey(spec.Name, din, q)
+		if err != nil {
+			return nil, err
+		}
+		raw = td.cachedQuery(key)
+	}
+	if raw == nil {
+		raw, err = td.QueryDevice(ctx, q, din)
+		if err != nil {
+			return nil, err
+		}
+		if key != "" {
+			td.storeQuery(key, raw, spec.CacheFor)
+		}
+	}
+
+	out = new(T)
+	err = json.Unmarshal(raw, out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type cachedQuery struct {
+	raw     json.RawMessage
+	expires time.Time
+}
+
+// queryCacheKey returns the key that a query's result is cached under.
+// It includes the vars, as the same query with different vars has a different result.
+func queryCacheKey(name, din string, q Query) (string, error) {
+	vars, err := q.wireVars()
+	if err != nil {
+		return "", err
+	}
+	return name + "\x00" + din + "\x00" + string(vars), nil
+}
+
+func (td *TEDApi) cachedQuery(key string) json.RawMessage {
+	td.cacheLock.Lock()
+	defer td.cacheLock.Unlock()
+
+	c, ok := td.cache[key]
+	if !ok || time.Now().After(c.expires) {
+		return nil
+	}
+	return c.raw
+}
+
+func (td *TEDApi) storeQuery(key string, raw json.RawMessage, cacheFor time.Duration) {
+	td.cacheLock.Lock()
+	defer td.cacheLock.Unlock()
+
+	if td.cache == nil {
+		td.cache = make(map[string]cachedQuery)
+	}
+	td.cache[key] = cachedQuery{raw: raw, expires: time.Now().Add(cacheFor)}
+}
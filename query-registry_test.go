@@ -0,0 +1,134 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/samthor/powerwall/protocol"
+)
+
+// registerForTest registers a query until the test ends, so tests can be run repeatedly.
+func registerForTest(t *testing.T, spec QuerySpec) error {
+	if err := RegisterQuery(spec); err != nil {
+		return err
+	}
+	t.Cleanup(func() {
+		registryLock.Lock()
+		defer registryLock.Unlock()
+		delete(registry, string(spec.Query.Signature))
+	})
+	return nil
+}
+
+func TestRegisterQuery(t *testing.T) {
+	q := Query{Query: "query Test { x }", Signature: []byte("test-register-signature")}
+	if err := registerForTest(t, QuerySpec{Name: "test", Query: q}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registerForTest(t, QuerySpec{Name: "again", Query: q}); err == nil {
+		t.Errorf("expected duplicate signature to fail")
+	}
+	if err := registerForTest(t, QuerySpec{Name: "unsigned", Query: Query{Query: "query X { x }"}}); err == nil {
+		t.Errorf("expected unsigned query to fail")
+	}
+	if spec, ok := LookupQuery(q); !ok || spec.Name != "test" {
+		t.Errorf("got %+v, %v", spec, ok)
+	}
+}
+
+func TestQueryIntoCachedTypes(t *testing.T) {
+	q := Query{Query: "query Cached { x }", Signature: []byte("test-cached-signature")}
+	err := registerForTest(t, QuerySpec{Name: "cached", Query: q, Target: TargetLeader, Type: reflect.TypeFor[StatusResponse](), CacheFor: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	td := &TEDApi{}
+	key, err := queryCacheKey("cached", "", q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	td.storeQuery(key, json.RawMessage(`{"system":{"time":"2025-06-01T12:00:00Z"}}`), time.Hour)
+
+	// a cached result can be decoded into any type, not only the registered one
+	status, err := QueryInto[StatusResponse](context.Background(), td, q, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if status.System.Time != "2025-06-01T12:00:00Z" {
+		t.Errorf("bad status: %+v", status.System)
+	}
+	raw, err := QueryInto[map[string]any](context.Background(), td, q, "")
+	if err != nil {
+		t.Fatal(err)
+	} else if _, ok := (*raw)["system"]; !ok {
+		t.Errorf("bad raw: %v", *raw)
+	}
+
+	if _, err := QueryInto[StatusResponse](context.Background(), td, q, "1232100-00-E--TG123456789012"); err == nil {
+		t.Errorf("expected leader-only query to reject a DIN")
+	}
+}
+
+func TestQueryIntoCachesByVars(t *testing.T) {
+	var queries int
+	s := newMessageGateway(t, func(req *protocol.MessageEnvelope) *protocol.MessageEnvelope {
+		queries++
+		vars := req.GetPayload().GetSend().GetB().GetValue()
+		return &protocol.MessageEnvelope{Payload: &protocol.QueryType{Recv: &protocol.PayloadString{Text: `{"vars":` + vars + `}`}}}
+	})
+	td := &TEDApi{BaseURL: s.URL, Secret: "secret"}
+
+	q := Query{Query: "query Vars($n:Int) { x }", Signature: []byte("test-vars-signature")}
+	err := registerForTest(t, QuerySpec{Name: "vars", Query: q, Target: TargetLeader, CacheFor: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		vars    string
+		want    string
+		queries int // total sent to the gateway afterwards
+	}{
+		{`{"n":1}`, `{"n":1}`, 1},
+		{`{"n":2}`, `{"n":2}`, 2},
+		{`{"n":1}`, `{"n":1}`, 2},    // cached
+		{`{ "n": 2 }`, `{"n":2}`, 2}, // same vars on the wire, so cached
+	}
+	for _, tt := range tests {
+		q.Vars = json.RawMessage(tt.vars)
+		out, err := QueryInto[struct{ Vars json.RawMessage }](context.Background(), td, q, "")
+		if err != nil {
+			t.Fatalf("%s: %v", tt.vars, err)
+		}
+		if string(out.Vars) != tt.want {
+			t.Errorf("%s: got vars %s, want %s", tt.vars, out.Vars, tt.want)
+		}
+		if queries != tt.queries {
+			t.Errorf("%s: got %d queries, want %d", tt.vars, queries, tt.queries)
+		}
+	}
+}
+
+func TestEsCanDevices(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{`{"PVAC":[{"a":1},{"a":2}]}`, 2},
+		{`{"PVAC":{"a":1}}`, 1},
+		{`{"PVAC":null}`, 0},
+		{`{"PVAC":[]}`, 0},
+	}
+	for _, tt := range tests {
+		var bus EsCanBus
+		if err := json.Unmarshal([]byte(tt.in), &bus); err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got := len(bus["PVAC"]); got != tt.want {
+			t.Errorf("%s: got %d devices, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package powerwall
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrBadSignature is matched by errors where the gateway rejected a query's signature.
+	// This typically means the query text or vars were changed after being signed.
+	ErrBadSignature = errors.New("query signature rejected")
+
+	// ErrUnsupportedField is matched by errors where the query asked for a field the firmware doesn't have.
+	ErrUnsupportedField = errors.New("unsupported query field")
+
+	// ErrProtocol is matched by errors where the gateway's reply didn't have the expected shape.
+	ErrProtocol = errors.New("unexpected reply from gateway")
+
+	// badSignaturePattern matches the gateway's message for a query whose signature didn't verify.
+	// Other messages that happen to mention a signature (e.g., an unknown field) don't match.
+	badSignaturePattern = regexp.MustCompile(`(?i)^(invalid|bad|unverified) (query )?signature\b|^signature (is invalid|verification failed|mismatch)\b`)
+
+	// unsupportedFieldPattern matches the standard GraphQL messages for a field or argument the schema doesn't have.
+	unsupportedFieldPattern = regexp.MustCompile(`^(Cannot query field|Unknown argument) "`)
+)
+
+// QueryResult is the decoded GraphQL response to a [Query].
+type QueryResult struct {
+	Data   json.RawMessage `json:"data,omitzero"`   // may be partial if there are Errors
+	Errors GraphQLErrors   `json:"errors,omitzero"` // reported by the gateway
+
+	Envelope Envelope `json:"-"` // routing of the response
+}
+
+// GraphQLError is a single error from a GraphQL response.
+type GraphQLError struct {
+	Message   string `json:"message"`
+	Path      []any  `json:"path,omitzero"` // field names (string) and list indexes (float64)
+	Locations []struct {
+		Line   int `json:"line"`
+		Column int `json:"column"`
+	} `json:"locations,omitzero"`
+	Extensions map[string]any `json:"extensions,omitzero"`
+}
+
+func (e GraphQLError) Error() string {
+	if len(e.Path) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf("%s (at %s)", e.Message, e.PathString())
+}
+
+// PathString returns the path to the failing field, e.g., "control.batteryBlocks[0].din".
+func (e GraphQLError) PathString() string {
+	var sb strings.Builder
+	for _, p := range e.Path {
+		switch p := p.(type) {
+		case string:
+			if sb.Len() != 0 {
+				sb.WriteByte('.')
+			}
+			sb.WriteString(p)
+		case float64:
+			fmt.Fprintf(&sb, "[%d]", int(p))
+		}
+	}
+	return sb.String()
+}
+
+func (e GraphQLError) Is(target error) bool {
+	switch target {
+	case ErrBadSignature:
+		return badSignaturePattern.MatchString(e.Message)
+	case ErrUnsupportedField:
+		return unsupportedFieldPattern.MatchString(e.Message)
+	}
+	return false
+}
+
+// GraphQLErrors is a list of errors from a GraphQL response.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	parts := make([]string, 0, len(e))
+	for _, each := range e {
+		parts = append(parts, each.Error())
+	}
+	return "graphql: " + strings.Join(parts, "; ")
+}
+
+func (e GraphQLErrors) Unwrap() []error {
+	out := make([]error, 0, len(e))
+	for _, each := range e {
+		out = append(out, each)
+	}
+	return out
+}
+
+// parseQueryResult decodes the text response from a query.
+// The gateway usually returns the data fields at the top level, but this also handles the standard {"data", "errors"} envelope.
+// Text that isn't a JSON object is an [ErrProtocol], unless it's the gateway's message for a bad signature.
+func parseQueryResult(text []byte) (res *QueryResult, err error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(text, &top); err != nil || top == nil {
+		msg := strings.TrimSpace(string(text))
+		if msg == "" {
+			return nil, fmt.Errorf("%w: empty query response", ErrProtocol)
+		} else if badSignaturePattern.MatchString(msg) {
+			return nil, fmt.Errorf("%w: %s", ErrBadSignature, msg)
+		}
+		return nil, fmt.Errorf("%w: query response isn't JSON: %.80q", ErrProtocol, msg)
+	}
+
+	res = &QueryResult{}
+	_, hasErrors := top["errors"]
+	if _, hasData := top["data"]; !hasErrors && !hasData {
+		res.Data = text
+		return res, nil
+	}
+
+	if raw, ok := top["errors"]; ok {
+		err = json.Unmarshal(raw, &res.Errors)
+		if err != nil {
+			return nil, fmt.Errorf("could not decode graphql errors: %w", err)
+		}
+		delete(top, "errors")
+	}
+
+	if raw, ok := top["data"]; ok && len(top) == 1 {
+		if !bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			res.Data = raw
+		}
+	} else if len(top) != 0 {
+		res.Data, err = json.Marshal(top)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
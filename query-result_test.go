@@ -0,0 +1,86 @@
+package powerwall
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseQueryResult(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		wantErr    error
+		wantData   string
+		wantErrors int
+	}{
+		{"bare data", `{"control":{"x":1}}`, nil, `{"control":{"x":1}}`, 0},
+		{"data envelope", `{"data":{"x":1}}`, nil, `{"x":1}`, 0},
+		{"null data", `{"data":null,"errors":[{"message":"boom"}]}`, nil, "", 1},
+		{"partial", `{"x":1,"errors":[{"message":"boom","path":["y",0]}]}`, nil, `{"x":1}`, 1},
+		{"empty", ``, ErrProtocol, "", 0},
+		{"whitespace", " \n", ErrProtocol, "", 0},
+		{"not JSON", `<html>bad gateway</html>`, ErrProtocol, "", 0},
+		{"JSON array", `[1]`, ErrProtocol, "", 0},
+		{"JSON null", `null`, ErrProtocol, "", 0},
+		{"plain text signature", `Invalid signature`, ErrBadSignature, "", 0},
+		{"other text mentioning signature", `no signature header`, ErrProtocol, "", 0},
+	}
+	for _, tt := range tests {
+		res, err := parseQueryResult([]byte(tt.text))
+		if tt.wantErr != nil {
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("%s: got err %v, want %v", tt.name, err, tt.wantErr)
+			}
+			if tt.wantErr == ErrProtocol && errors.Is(err, ErrBadSignature) {
+				t.Errorf("%s: protocol error classified as bad signature: %v", tt.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected err %v", tt.name, err)
+			continue
+		}
+		if string(res.Data) != tt.wantData {
+			t.Errorf("%s: got data %s, want %s", tt.name, res.Data, tt.wantData)
+		}
+		if len(res.Errors) != tt.wantErrors {
+			t.Errorf("%s: got %d errors, want %d", tt.name, len(res.Errors), tt.wantErrors)
+		}
+	}
+
+	if _, err := parseQueryResult([]byte(`{"errors":"nope"}`)); err == nil {
+		t.Errorf("expected error for malformed errors")
+	}
+}
+
+func TestGraphQLErrorIs(t *testing.T) {
+	tests := []struct {
+		message   string
+		signature bool
+		field     bool
+	}{
+		{"Invalid signature", true, false},
+		{"invalid query signature for DeviceControllerQuery", true, false},
+		{"Signature verification failed", true, false},
+		{`Cannot query field "signatureType" on type "System".`, false, true},
+		{`Unknown argument "names" on field "signals".`, false, true},
+		{"failed to read signature file", false, false},
+		{"internal error", false, false},
+	}
+	for _, tt := range tests {
+		e := GraphQLError{Message: tt.message}
+		if got := errors.Is(e, ErrBadSignature); got != tt.signature {
+			t.Errorf("%q: Is(ErrBadSignature) = %v, want %v", tt.message, got, tt.signature)
+		}
+		if got := errors.Is(GraphQLErrors{e}, ErrUnsupportedField); got != tt.field {
+			t.Errorf("%q: Is(ErrUnsupportedField) = %v, want %v", tt.message, got, tt.field)
+		}
+	}
+}
+
+func TestGraphQLErrorPath(t *testing.T) {
+	e := GraphQLError{Message: "boom", Path: []any{"control", "batteryBlocks", 0.0, "din"}}
+	if got, want := e.Error(), "boom (at control.batteryBlocks[0].din)"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,91 @@
+package powerwall
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+var (
+	// ErrSignatureMismatch is returned by [VerifyQuery] if the query doesn't match its signature.
+	ErrSignatureMismatch = errors.New("query signature does not match")
+)
+
+// QuerySignature is a parsed [Query.Signature].
+type QuerySignature struct {
+	R, S *big.Int
+}
+
+// ParseQuerySignature parses a query's DER-encoded ECDSA signature (an ASN.1 SEQUENCE of two integers).
+func ParseQuerySignature(sig []byte) (out *QuerySignature, err error) {
+	var parsed struct {
+		R, S *big.Int
+	}
+	rest, err := asn1.Unmarshal(sig, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("bad query signature: %w", err)
+	} else if len(rest) != 0 {
+		return nil, fmt.Errorf("bad query signature: %d trailing bytes", len(rest))
+	} else if parsed.R.Sign() <= 0 || parsed.S.Sign() <= 0 {
+		return nil, fmt.Errorf("bad query signature: non-positive value")
+	}
+	return &QuerySignature{R: parsed.R, S: parsed.S}, nil
+}
+
+// ParseQueryPublicKey parses an ECDSA public key in PEM or DER (PKIX) form, for use with [VerifyQuery].
+func ParseQueryPublicKey(b []byte) (pub *ecdsa.PublicKey, err error) {
+	if block, _ := pem.Decode(b); block != nil {
+		b = block.Bytes
+	}
+	key, err := x509.ParsePKIXPublicKey(b)
+	if err != nil {
+		return nil, err
+	}
+	pub, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an ECDSA public key: %T", key)
+	}
+	return pub, nil
+}
+
+// VerifyQuery checks a query's signature under the given public key.
+//
+// The signed message layout is unverified, as Tesla's key isn't known and so no real signature has been checked.
+// This assumes the message is the exact bytes sent to the gateway: the query text followed by the vars as encoded on
+// the wire (compacted JSON, or "{}" if there are none), hashed with the hash paired with the key's curve (e.g.,
+// SHA-512 for P-521). If that's wrong, even a genuine query will fail.
+//
+// Returns [ErrSignatureMismatch] if it doesn't match.
+func VerifyQuery(q Query, pub *ecdsa.PublicKey) error {
+	sig, err := ParseQuerySignature(q.Signature)
+	if err != nil {
+		return err
+	}
+	vars, err := q.wireVars()
+	if err != nil {
+		return err
+	}
+
+	var h crypto.Hash
+	switch bits := pub.Curve.Params().BitSize; {
+	case bits <= 256:
+		h = crypto.SHA256
+	case bits <= 384:
+		h = crypto.SHA384
+	default:
+		h = crypto.SHA512
+	}
+
+	hasher := h.New()
+	hasher.Write([]byte(q.Query))
+	hasher.Write(vars)
+	if !ecdsa.Verify(pub, hasher.Sum(nil), sig.R, sig.S) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
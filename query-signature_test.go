@@ -0,0 +1,109 @@
+package powerwall
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestBuiltinQuerySignatures(t *testing.T) {
+	other, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+	}{
+		{"status", QueryStatus},
+		{"components", QueryComponents},
+		{"deviceController", QueryDeviceController},
+	}
+	for _, tt := range tests {
+		sig, err := ParseQuerySignature(tt.q.Signature)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if sig.R.BitLen() > 521 || sig.S.BitLen() > 521 {
+			t.Errorf("%s: signature too large for P-521", tt.name)
+		}
+
+		// the vars sent are exactly the compacted form, so whitespace in the source doesn't matter
+		vars, err := tt.q.wireVars()
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var want bytes.Buffer
+		if tt.q.Vars == nil {
+			want.WriteString("{}")
+		} else {
+			json.Compact(&want, tt.q.Vars)
+		}
+		if !bytes.Equal(vars, want.Bytes()) {
+			t.Errorf("%s: wire vars %s, want %s", tt.name, vars, want.Bytes())
+		}
+
+		// Tesla's key isn't public, but any other key must not verify
+		if err := VerifyQuery(tt.q, &other.PublicKey); !errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("%s: got %v, want ErrSignatureMismatch", tt.name, err)
+		}
+	}
+}
+
+func TestVerifyQuery(t *testing.T) {
+	sign := func(key *ecdsa.PrivateKey, h crypto.Hash, message string) []byte {
+		hasher := h.New()
+		hasher.Write([]byte(message))
+		sig, err := ecdsa.SignASN1(rand.Reader, key, hasher.Sum(nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	for _, tc := range []struct {
+		curve elliptic.Curve
+		hash  crypto.Hash
+	}{
+		{elliptic.P256(), crypto.SHA256},
+		{elliptic.P384(), crypto.SHA384},
+		{elliptic.P521(), crypto.SHA512},
+	} {
+		key, err := ecdsa.GenerateKey(tc.curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := "query Q($a:String){x(a:$a)}"
+		rawVars := json.RawMessage("{\"a\": \"<b>\"}")
+
+		tests := []struct {
+			name    string
+			q       Query
+			wantErr error
+		}{
+			{"wire vars", Query{Query: text, Vars: rawVars, Signature: sign(key, tc.hash, text+`{"a":"\u003cb\u003e"}`)}, nil},
+			{"unescaped vars aren't sent", Query{Query: text, Vars: rawVars, Signature: sign(key, tc.hash, text+`{"a":"<b>"}`)}, ErrSignatureMismatch},
+			{"nil vars", Query{Query: text, Signature: sign(key, tc.hash, text+"{}")}, nil},
+			{"raw vars aren't sent", Query{Query: text, Vars: rawVars, Signature: sign(key, tc.hash, text+string(rawVars))}, ErrSignatureMismatch},
+			{"query alone isn't sent", Query{Query: text, Signature: sign(key, tc.hash, text)}, ErrSignatureMismatch},
+			{"altered query", Query{Query: text + " ", Signature: sign(key, tc.hash, text+"{}")}, ErrSignatureMismatch},
+		}
+		for _, tt := range tests {
+			err := VerifyQuery(tt.q, &key.PublicKey)
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("%s %s: got %v, want %v", tc.curve.Params().Name, tt.name, err, tt.wantErr)
+			}
+		}
+	}
+
+	if err := VerifyQuery(Query{Query: "q", Signature: []byte("nope")}, nil); err == nil || errors.Is(err, ErrSignatureMismatch) {
+		t.Errorf("expected parse error, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package powerwall
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/samthor/powerwall/protocol"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeLeaderDIN is the DIN of the leader in fake gateways.
+const fakeLeaderDIN = "1707000-11-J--TG123456789012"
+
+// newMessageGateway starts a gateway that answers the DIN lookup with [fakeLeaderDIN], and each message (to the leader,
+// or routed to another device) with the payload from reply, sent from the addressed device back to the sender.
+// Requests must use the Basic-auth secret "secret".
+func newMessageGateway(t *testing.T, reply func(req *protocol.MessageEnvelope) *protocol.MessageEnvelope) *httptest.Server {
+	// the gateway expects unpadded URL-safe base64, so this can't use r.BasicAuth
+	want := "Basic " + base64.RawURLEncoding.EncodeToString([]byte("Tesla_Energy_Device:secret"))
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if r.Header.Get("Authorization") != want {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tedapi/din", func(w http.ResponseWriter, r *http.Request) {
+		if authorized(w, r) {
+			io.WriteString(w, fakeLeaderDIN)
+		}
+	})
+	message := func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		var req protocol.Message
+		if err := proto.Unmarshal(body, &req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		env := reply(req.GetMessage())
+		env.DeliveryChannel = req.GetMessage().GetDeliveryChannel()
+		env.Sender = req.GetMessage().GetRecipient()
+		env.Recipient = req.GetMessage().GetSender()
+		b, _ := proto.Marshal(&protocol.Message{Message: env, Tail: req.GetTail()})
+		w.Write(b)
+	}
+	mux.HandleFunc("POST /tedapi/v1", message)
+	mux.HandleFunc("POST /tedapi/device/{din}/v1", message)
+
+	s := httptest.NewTLSServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newConnectProxy starts an HTTP CONNECT proxy, counting the tunnels it opens.
+func newConnectProxy(t *testing.T) (s *httptest.Server, tunnels *atomic.Int32) {
+	tunnels = &atomic.Int32{}
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer upstream.Close()
+		w.WriteHeader(http.StatusOK)
+		conn, buf, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		tunnels.Add(1)
+
+		go io.Copy(upstream, buf)
+		io.Copy(conn, upstream)
+	}))
+	t.Cleanup(s.Close)
+	return s, tunnels
+}
+
+func TestProxy(t *testing.T) {
+	gateway := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, fakeLeaderDIN)
+	}))
+	t.Cleanup(gateway.Close)
+	proxy, tunnels := newConnectProxy(t)
+
+	td := &TEDApi{BaseURL: gateway.URL, Secret: "secret", Proxy: proxy.URL}
+	din, err := td.LeaderDIN(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	} else if din != fakeLeaderDIN {
+		t.Errorf("got DIN %q", din)
+	}
+	if tunnels.Load() != 1 {
+		t.Errorf("got %d tunnels, want 1", tunnels.Load())
+	}
+
+	for _, bad := range []string{"ftp://proxy.local", "proxy.local:1080", "http://%zz"} {
+		td := &TEDApi{BaseURL: gateway.URL, Secret: "secret", Proxy: bad}
+		if _, err := td.LeaderDIN(context.Background()); err == nil {
+			t.Errorf("%s: connected without proxy", bad)
+		}
+	}
+}
+
+func TestBuildUrl(t *testing.T) {
+	tests := []struct {
+		remote string
+		want   string // empty if an error
+	}{
+		{"192.168.91.1:443", "https://192.168.91.1:443/tedapi/din"},
+		{"gateway.local", "https://gateway.local/tedapi/din"},
+		{"2001:db8::1", "https://[2001:db8::1]/tedapi/din"},
+		{"fe80::1%eth0", "https://[fe80::1%25eth0]/tedapi/din"},
+		{"[fe80::1%eth0]:443", "https://[fe80::1%25eth0]:443/tedapi/din"},
+		{"[2001:db8::1]:8443", "https://[2001:db8::1]:8443/tedapi/din"},
+		{"https://proxy.local", "https://proxy.local/tedapi/din"},
+		{"http://proxy.local:8080/", "http://proxy.local:8080/tedapi/din"},
+		{"https://proxy.local/site-a", "https://proxy.local/site-a/tedapi/din"},
+		{"https://proxy.local/site-a/", "https://proxy.local/site-a/tedapi/din"},
+		{"https://proxy.local/site-a/tedapi", "https://proxy.local/site-a/tedapi/din"},
+		{"https://proxy.local/site-a/tedapi/v1", "https://proxy.local/site-a/tedapi/din"},
+		{"https://proxy.local/site%20a/tedapi/v1/", "https://proxy.local/site%20a/tedapi/din"},
+		{"https://[fe80::1%25eth0]:443/site-a", "https://[fe80::1%25eth0]:443/site-a/tedapi/din"},
+		{"ftp://proxy.local", ""},
+		{"https://", ""},
+		{"https://proxy.local/?site=a", ""},
+		{"https://proxy.local/#a", ""},
+		{"https://[fe80::1%eth0]/", ""}, // zone must be escaped in a URL
+	}
+	for _, tt := range tests {
+		got, err := buildUrl(tt.remote, "/tedapi/din")
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("%s: got %q, want error", tt.remote, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.remote, err)
+		} else if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.remote, got, tt.want)
+		} else if _, err := http.NewRequest(http.MethodGet, got, nil); err != nil {
+			t.Errorf("%s: built an invalid URL: %v", tt.remote, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package powerwall
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+// RemoteHealth is what's known about one of a [TEDApi]'s remotes.
+type RemoteHealth struct {
+	Remote    string    `json:"remote"`
+	Active    bool      `json:"active"`             // preferred for the next request
+	Healthy   bool      `json:"healthy"`            // whether the last attempt got an HTTP response
+	LastError string    `json:"lastError,omitzero"` // from the last attempt, if it failed
+	LastCheck time.Time `json:"lastCheck,omitzero"` // zero if never tried
+}
+
+// RemoteHealth reports on each remote, in configured order.
+// Remotes which haven't been tried yet are reported as healthy.
+func (td *TEDApi) RemoteHealth() []RemoteHealth {
+	td.healthLock.Lock()
+	defer td.healthLock.Unlock()
+
+	remotes := td.remotes()
+	active := td.activeRemoteLocked(remotes)
+
+	out := make([]RemoteHealth, 0, len(remotes))
+	for _, r := range remotes {
+		h := RemoteHealth{Remote: r, Healthy: true}
+		if prev, ok := td.health[r]; ok {
+			h = *prev
+		}
+		h.Active = r == active
+		out = append(out, h)
+	}
+	return out
+}
+
+// StartHealthChecks probes every remote each interval until ctx is done, by fetching the DIN.
+// If an earlier (more preferred) remote recovers, it becomes active again. A remote that rejects our credentials is
+// unhealthy.
+// The checks don't otherwise affect the [TEDApi]: they never refresh the secret or forget the DIN.
+// This is only useful with [TEDApi.Remotes].
+func (td *TEDApi) StartHealthChecks(ctx context.Context, interval time.Duration) {
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+			}
+
+			for _, remote := range td.remotes() {
+				checkCtx, cancel := context.WithTimeout(ctx, interval)
+				err := td.checkRemote(checkCtx, remote)
+				cancel()
+				if ctx.Err() != nil {
+					return
+				}
+
+				td.markRemote(remote, err)
+				if err == nil {
+					td.preferRemote(remote)
+				}
+			}
+		}
+	}()
+}
+
+// checkRemote probes remote for [TEDApi.StartHealthChecks].
+// Any HTTP response other than an auth failure means it works: e.g., a 429 is just the gateway rate-limiting us.
+func (td *TEDApi) checkRemote(ctx context.Context, remote string) error {
+	_, err := td.probeDIN(ctx, remote)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && !isAuthFailure(err) {
+		return nil
+	}
+	return err
+}
+
+// activeRemoteLocked returns the active remote, which is the first configured unless another has since worked.
+func (td *TEDApi) activeRemoteLocked(remotes []string) string {
+	if slices.Contains(remotes, td.activeRemote) {
+		return td.activeRemote
+	}
+	return remotes[0]
+}
+
+// remoteOrder returns the remotes to try, starting with the active one, but with any known to be unhealthy last.
+func (td *TEDApi) remoteOrder() []string {
+	td.healthLock.Lock()
+	defer td.healthLock.Unlock()
+
+	remotes := td.remotes()
+	active := td.activeRemoteLocked(remotes)
+
+	out := make([]string, 0, len(remotes))
+	out = append(out, active)
+	for _, r := range remotes {
+		if r != active {
+			out = append(out, r)
+		}
+	}
+	slices.SortStableFunc(out, func(a, b string) int {
+		return td.unhealthyLocked(a) - td.unhealthyLocked(b)
+	})
+	return out
+}
+
+func (td *TEDApi) unhealthyLocked(remote string) int {
+	if h, ok := td.health[remote]; ok && !h.Healthy {
+		return 1
+	}
+	return 0
+}
+
+// markRemote records the result of an attempt on remote, making it active if it worked.
+func (td *TEDApi) markRemote(remote string, err error) {
+	td.healthLock.Lock()
+	defer td.healthLock.Unlock()
+
+	if td.health == nil {
+		td.health = make(map[string]*RemoteHealth)
+	}
+	h := &RemoteHealth{Remote: remote, Healthy: err == nil, LastCheck: time.Now()}
+	if err != nil {
+		h.LastError = err.Error()
+	}
+	td.health[remote] = h
+
+	if err != nil {
+		return
+	}
+	remotes := td.remotes()
+	if prev := td.activeRemoteLocked(remotes); prev != remote {
+		if h, ok := td.health[prev]; !ok || !h.Healthy {
+			td.logger().Info("switched remote", "from", prev, "to", remote)
+			td.activeRemote = remote
+		}
+	}
+}
+
+// preferRemote makes remote active if it's configured before the current active remote.
+func (td *TEDApi) preferRemote(remote string) {
+	td.healthLock.Lock()
+	defer td.healthLock.Unlock()
+
+	remotes := td.remotes()
+	prev := td.activeRemoteLocked(remotes)
+	if slices.Index(remotes, remote) < slices.Index(remotes, prev) {
+		td.logger().Info("switched remote", "from", prev, "to", remote)
+		td.activeRemote = remote
+	}
+}
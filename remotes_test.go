@@ -0,0 +1,86 @@
+package powerwall
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// countingSecret is a [SecretProvider] that counts how often it's loaded.
+type countingSecret struct {
+	loads int
+}
+
+func (c *countingSecret) LoadSecret(ctx context.Context) (Secret, error) {
+	c.loads++
+	return "secret", nil
+}
+
+func TestCheckRemote(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		healthy bool
+	}{
+		{"ok", http.StatusOK, true},
+		{"rate limited", http.StatusTooManyRequests, true},
+		{"unauthorized", http.StatusUnauthorized, false},
+		{"forbidden", http.StatusForbidden, false},
+	}
+	for _, tt := range tests {
+		s := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			io.WriteString(w, fakeLeaderDIN)
+		}))
+
+		provider := &countingSecret{}
+		td := &TEDApi{Remotes: []string{s.URL}, SecretProvider: provider, internalDIN: fakeLeaderDIN}
+		err := td.checkRemote(context.Background(), s.URL)
+		if healthy := err == nil; healthy != tt.healthy {
+			t.Errorf("%s: got err %v, want healthy=%v", tt.name, err, tt.healthy)
+		}
+		if provider.loads != 1 {
+			t.Errorf("%s: secret loaded %d times, want once", tt.name, provider.loads)
+		}
+		if td.internalDIN != fakeLeaderDIN {
+			t.Errorf("%s: DIN was forgotten", tt.name)
+		}
+		s.Close()
+	}
+
+	// an unreachable remote is unhealthy
+	td := &TEDApi{Secret: "secret"}
+	if err := td.checkRemote(context.Background(), "https://127.0.0.1:1"); err == nil {
+		t.Errorf("unreachable remote was healthy")
+	}
+}
+
+func TestFailover(t *testing.T) {
+	down := httptest.NewTLSServer(http.NotFoundHandler())
+	down.Close()
+	up := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, fakeLeaderDIN)
+	}))
+	t.Cleanup(up.Close)
+
+	var logs bytes.Buffer
+	td := &TEDApi{Remotes: []string{down.URL, up.URL}, Secret: "secret", Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	if din, err := td.LeaderDIN(context.Background()); err != nil || din != fakeLeaderDIN {
+		t.Fatalf("got %q, %v", din, err)
+	}
+
+	health := td.RemoteHealth()
+	if len(health) != 2 || health[0].Healthy || !health[1].Active || !health[1].Healthy {
+		t.Errorf("got health %+v", health)
+	}
+	for _, want := range []string{"remote failed, trying next", "switched remote"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("logs missing %q: %s", want, logs.String())
+		}
+	}
+}
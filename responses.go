@@ -0,0 +1,84 @@
+package powerwall
+
+// StatusResponse is the decoded response to [QueryStatus].
+// Only the commonly used fields are included; register your own type to decode more.
+type StatusResponse struct {
+	Control struct {
+		Alerts struct {
+			Active []string `json:"active"`
+		} `json:"alerts"`
+		BatteryBlocks []struct {
+			DIN            string   `json:"din"`
+			DisableReasons []string `json:"disableReasons"`
+		} `json:"batteryBlocks"`
+		Islanding struct {
+			ContactorClosed    bool     `json:"contactorClosed"`
+			CustomerIslandMode string   `json:"customerIslandMode"`
+			DisableReasons     []string `json:"disableReasons"`
+			GridOK             bool     `json:"gridOK"`
+			MicroGridOK        bool     `json:"microGridOK"`
+		} `json:"islanding"`
+		MeterAggregates []struct {
+			Location   string   `json:"location"`
+			RealPowerW *float64 `json:"realPowerW"`
+		} `json:"meterAggregates"`
+		PVInverters []struct {
+			// TODO: not sure what else goes here - maybe for PW2?
+			DIN            string   `json:"din"`
+			DisableReasons []string `json:"disableReasons"`
+		} `json:"pvInverters"`
+		SiteShutdown struct {
+			IsShutdown bool     `json:"isShutDown"`
+			Reasons    []string `json:"reasons"`
+		} `json:"siteShutdown"`
+		SystemStatus struct {
+			// these usually show up as int but rarely have e.g., .0000000004
+			NominalEnergyRemainingWh *float64 `json:"nominalEnergyRemainingWh"`
+			NominalFullPackEnergyWh  *float64 `json:"nominalFullPackEnergyWh"`
+		} `json:"systemStatus"`
+	} `json:"control"`
+
+	System struct {
+		Time               string `json:"time"`
+		UpdateUrgencyCheck struct {
+			Version struct {
+				Version string `json:"version"`
+				GitHash string `json:"gitHash"`
+			} `json:"version"`
+		} `json:"updateUrgencyCheck"`
+	} `json:"system"`
+
+	Neurio struct {
+		Pairings []struct {
+			Serial string `json:"serial"`
+		} `json:"pairings"`
+	} `json:"neurio"`
+
+	EsCan struct {
+		Bus         EsCanBus `json:"bus"`
+		Enumeration struct {
+			InProgress bool `json:"inProgress"`
+			NumACPW    int  `json:"numACPW"`
+			NumPVI     int  `json:"numPVI"`
+		} `json:"enumeration"`
+	} `json:"esCan"`
+}
+
+// DeviceControllerResponse is the decoded response to [QueryDeviceController].
+// It includes everything in [StatusResponse] plus some extra fields.
+type DeviceControllerResponse struct {
+	StatusResponse
+
+	TeslaRemoteMeter struct {
+		Meters []struct {
+			DIN     string `json:"din"`
+			Reading struct {
+				FirmwareVersion string `json:"firmwareVersion"`
+			} `json:"reading"`
+		} `json:"meters"`
+	} `json:"teslaRemoteMeter"`
+
+	Components struct {
+		MSA []Component `json:"msa"`
+	} `json:"components"`
+}
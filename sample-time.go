@@ -0,0 +1,80 @@
+package powerwall
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultMaxClockSkew is a reasonable limit to pass to [SampleTime.CheckClockSkew].
+	DefaultMaxClockSkew = time.Minute
+)
+
+// SampleTime records when a status sample was taken, by both the gateway and us.
+type SampleTime struct {
+	GatewayTime  time.Time     `json:"gatewayTime,omitzero"` // gateway's own clock ("system.time")
+	SentTime     time.Time     `json:"sentTime,omitzero"`    // local clock when the request was sent
+	ReceivedTime time.Time     `json:"receivedTime"`         // local clock when the response arrived
+	BusAge       time.Duration `json:"busAge,omitzero"`      // age of the oldest esCan measurement at GatewayTime
+}
+
+// ClockSkew returns how far ahead the gateway's clock is of ours (negative if behind).
+// The gateway's time is compared to the middle of the request, so this is only accurate to within half of
+// [SampleTime.Latency]. It's zero if the gateway didn't report its time.
+func (t SampleTime) ClockSkew() time.Duration {
+	if t.GatewayTime.IsZero() {
+		return 0
+	}
+	return t.GatewayTime.Sub(t.ReceivedTime.Add(-t.Latency() / 2))
+}
+
+// Latency returns the round trip time of the request, or zero if SentTime isn't known.
+func (t SampleTime) Latency() time.Duration {
+	if t.SentTime.IsZero() {
+		return 0
+	}
+	return t.ReceivedTime.Sub(t.SentTime)
+}
+
+// CheckClockSkew returns a [ClockSkewError] if the gateway's clock is more than max away from ours.
+// The library doesn't call this itself: callers decide whether (and how) to warn.
+// To avoid false alarms, max is widened by half of [SampleTime.Latency].
+func (t SampleTime) CheckClockSkew(max time.Duration) error {
+	skew := t.ClockSkew()
+	max += t.Latency() / 2
+	if skew > max || skew < -max {
+		return &ClockSkewError{Skew: skew, Max: max}
+	}
+	return nil
+}
+
+// ClockSkewError is returned by [SampleTime.CheckClockSkew].
+type ClockSkewError struct {
+	Skew time.Duration
+	Max  time.Duration
+}
+
+func (e *ClockSkewError) Error() string {
+	return fmt.Sprintf("gateway clock skew %v exceeds %v", e.Skew, e.Max)
+}
+
+// parseGatewayTime parses a timestamp as reported by the gateway, returning zero if it's missing or invalid.
+func parseGatewayTime(s string) time.Time {
+	t, _ := time.Parse(time.RFC3339Nano, s)
+	return t
+}
+
+// oldestRx returns the age, relative to now, of the oldest "lastRxTime" on the bus.
+func (b EsCanBus) oldestRx(now time.Time) (age time.Duration) {
+	for _, devices := range b {
+		for _, dev := range devices {
+			for key := range dev {
+				rx := parseGatewayTime(dev.Message(key).Text("lastRxTime"))
+				if !rx.IsZero() {
+					age = max(age, now.Sub(rx))
+				}
+			}
+		}
+	}
+	return age
+}
@@ -0,0 +1,34 @@
+package powerwall
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSampleTimeClockSkew(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		t        SampleTime
+		wantSkew time.Duration
+		wantErr  bool
+	}{
+		{"no gateway time", SampleTime{ReceivedTime: base}, 0, false},
+		{"in sync", SampleTime{GatewayTime: base, ReceivedTime: base}, 0, false},
+		{"ahead", SampleTime{GatewayTime: base.Add(2 * time.Minute), ReceivedTime: base}, 2 * time.Minute, true},
+		{"behind", SampleTime{GatewayTime: base.Add(-2 * time.Minute), ReceivedTime: base}, -2 * time.Minute, true},
+		{"latency not skew", SampleTime{GatewayTime: base.Add(time.Second), SentTime: base, ReceivedTime: base.Add(2 * time.Second)}, 0, false},
+		{"slow request widens limit", SampleTime{GatewayTime: base, SentTime: base.Add(-3 * time.Minute), ReceivedTime: base.Add(time.Minute)}, time.Minute, false},
+	}
+	for _, tt := range tests {
+		if got := tt.t.ClockSkew(); got != tt.wantSkew {
+			t.Errorf("%s: got skew %v, want %v", tt.name, got, tt.wantSkew)
+		}
+		err := tt.t.CheckClockSkew(DefaultMaxClockSkew)
+		var skewErr *ClockSkewError
+		if errors.As(err, &skewErr) != tt.wantErr {
+			t.Errorf("%s: got err %v, want err %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package powerwall
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	redacted = "[redacted]"
+)
+
+// Secret is a password which redacts itself when printed, encoded as JSON or logged with [log/slog].
+// Use [Secret.Reveal] to get the real value.
+type Secret string
+
+// Reveal returns the real value of the secret.
+func (s Secret) Reveal() string {
+	return string(s)
+}
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) GoString() string {
+	return fmt.Sprintf("%q", s.String())
+}
+
+// Format redacts the secret for every verb, including %v, %+v, %#v, %s and %q.
+func (s Secret) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 'q':
+		fmt.Fprintf(f, "%q", s.String())
+	case 'v':
+		if f.Flag('#') {
+			io.WriteString(f, s.GoString())
+			return
+		}
+		fallthrough
+	default:
+		io.WriteString(f, s.String())
+	}
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("%q", s.String())), nil
+}
+
+func (s Secret) LogValue() slog.Value {
+	return slog.StringValue(s.String())
+}
+
+// SecretProvider supplies the gateway's secret.
+// A [TEDApi] loads it on first use, and again after an auth failure, so it can pick up a changed secret.
+type SecretProvider interface {
+	LoadSecret(ctx context.Context) (Secret, error)
+}
+
+// LoadSecret makes a [Secret] a [SecretProvider] for itself.
+func (s Secret) LoadSecret(ctx context.Context) (Secret, error) {
+	return s, nil
+}
+
+// EnvSecret is a [SecretProvider] that reads the named environment variable.
+type EnvSecret string
+
+func (e EnvSecret) LoadSecret(ctx context.Context) (Secret, error) {
+	v, ok := os.LookupEnv(string(e))
+	if !ok {
+		return "", fmt.Errorf("secret env var %s not set", string(e))
+	}
+	return Secret(v), nil
+}
+
+// FileSecret is a [SecretProvider] that reads the named file, trimming whitespace.
+type FileSecret string
+
+func (f FileSecret) LoadSecret(ctx context.Context) (Secret, error) {
+	b, err := os.ReadFile(string(f))
+	if err != nil {
+		return "", err
+	}
+	return Secret(strings.TrimSpace(string(b))), nil
+}
+
+// CommandSecret is a [SecretProvider] that runs a command (e.g., a password manager's CLI) and uses its output, trimming whitespace.
+type CommandSecret []string
+
+func (c CommandSecret) LoadSecret(ctx context.Context) (Secret, error) {
+	if len(c) == 0 {
+		return "", fmt.Errorf("no secret command")
+	}
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, c[0], c[1:]...)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// the output isn't included, in case it's a partial secret
+		return "", fmt.Errorf("secret command %s failed: %w (%s)", c[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return Secret(strings.TrimSpace(string(out))), nil
+}
+
+// loadedSecret holds a secret from a [SecretProvider].
+// It's kept behind a pointer so that printing a [TEDApi] (even with %+v, which can't call Secret's methods on an
+// unexported field) shows only an address.
+type loadedSecret struct {
+	value Secret
+}
+
+// loadSecret returns the secret to use: from SecretProvider (cached until refreshSecret) if set, otherwise Secret.
+// Concurrent callers share one load, but each can give up via its own ctx.
+func (td *TEDApi) loadSecret(ctx context.Context) (s Secret, err error) {
+	if td.SecretProvider == nil {
+		return td.Secret, nil
+	}
+
+	td.secretLock.Lock()
+	if td.secret != nil {
+		s = td.secret.value
+		td.secretLock.Unlock()
+		return s, nil
+	}
+	call := td.startSecretLoadLocked(ctx)
+	td.secretLock.Unlock()
+
+	return call.wait(ctx)
+}
+
+// refreshSecret reloads the secret after stale was rejected, returning whether it changed.
+// If another caller already refreshed it (or is doing so), that result is used.
+func (td *TEDApi) refreshSecret(ctx context.Context, stale Secret) (fresh Secret, changed bool) {
+	if td.SecretProvider == nil {
+		return stale, false
+	}
+
+	td.secretLock.Lock()
+	if td.secret != nil && td.secret.value != stale {
+		fresh = td.secret.value
+		td.secretLock.Unlock()
+		return fresh, true
+	}
+	call := td.startSecretLoadLocked(ctx)
+	td.secretLock.Unlock()
+
+	fresh, err := call.wait(ctx)
+	if err != nil {
+		return stale, false
+	}
+	return fresh, fresh != stale
+}
+
+// startSecretLoadLocked returns the in-flight load of the secret, starting one (bounded by the request timeout) if
+// needed. Must be called with secretLock held.
+func (td *TEDApi) startSecretLoadLocked(ctx context.Context) *inflight[Secret] {
+	if td.secretCall == nil {
+		td.secretCall = &inflight[Secret]{}
+		td.secretCall.start(ctx, td.timeout(), td.runSecretLoad)
+	}
+	return td.secretCall
+}
+
+func (td *TEDApi) runSecretLoad(ctx context.Context) (s Secret, err error) {
+	s, err = td.SecretProvider.LoadSecret(ctx)
+
+	td.secretLock.Lock()
+	defer td.secretLock.Unlock()
+
+	td.secretCall = nil
+	if err == nil {
+		td.secret = &loadedSecret{value: s}
+	}
+	return s, err
+}
+
+// isAuthFailure returns whether err is the gateway rejecting our credentials.
+func isAuthFailure(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && (statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden)
+}
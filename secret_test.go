@@ -0,0 +1,146 @@
+package powerwall
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSecretFormat(t *testing.T) {
+	s := Secret("hunter2")
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", redacted},
+		{"%+v", redacted},
+		{"%#v", `"[redacted]"`},
+		{"%s", redacted},
+		{"%q", `"[redacted]"`},
+		{"%x", redacted},
+		{"%d", redacted},
+		{"%10s", redacted},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, s); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.format, got, tt.want)
+		}
+	}
+
+	if got := fmt.Sprintf("%v|%q", Secret(""), Secret("")); got != `|""` {
+		t.Errorf("empty secret: got %q", got)
+	}
+	if got := s.Reveal(); got != "hunter2" {
+		t.Errorf("got Reveal %q", got)
+	}
+
+	b, _ := json.Marshal(struct{ S Secret }{s})
+	if string(b) != `{"S":"[redacted]"}` {
+		t.Errorf("got JSON %s", b)
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("msg", "secret", s)
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("slog leaked secret: %s", buf.String())
+	}
+}
+
+func TestTEDApiFormatHidesSecret(t *testing.T) {
+	td := &TEDApi{Secret: "hunter2", SecretProvider: Secret("hunter3")}
+	if _, err := td.loadSecret(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// a top-level pointer prints its struct's fields, as if formatting *td
+	for _, format := range []string{"%v", "%+v", "%#v"} {
+		if got := fmt.Sprintf(format, td); strings.Contains(got, "hunter") {
+			t.Errorf("%s leaked secret: %s", format, got)
+		}
+	}
+}
+
+// blockingSecret is a [SecretProvider] that waits for release, counting how often it's loaded.
+type blockingSecret struct {
+	release chan struct{}
+	loads   atomic.Int32
+}
+
+func (b *blockingSecret) LoadSecret(ctx context.Context) (Secret, error) {
+	b.loads.Add(1)
+	select {
+	case <-b.release:
+		return "secret", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestLoadSecretShared(t *testing.T) {
+	provider := &blockingSecret{release: make(chan struct{})}
+	td := &TEDApi{SecretProvider: provider}
+
+	// a caller gives up on its own ctx, even though the load is still running
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := td.loadSecret(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want deadline exceeded", err)
+	}
+
+	// later callers share the same load
+	results := make(chan error)
+	for range 3 {
+		go func() {
+			s, err := td.loadSecret(context.Background())
+			if err == nil && s != "secret" {
+				err = fmt.Errorf("got secret %q", s.Reveal())
+			}
+			results <- err
+		}()
+	}
+	close(provider.release)
+	for range 3 {
+		if err := <-results; err != nil {
+			t.Error(err)
+		}
+	}
+	if got := provider.loads.Load(); got != 1 {
+		t.Errorf("got %d loads, want 1", got)
+	}
+}
+
+// rotatingSecret is a [SecretProvider] that returns each of its secrets in turn, then the last one.
+type rotatingSecret struct {
+	secrets []Secret
+	loads   atomic.Int32
+}
+
+func (r *rotatingSecret) LoadSecret(ctx context.Context) (Secret, error) {
+	i := int(r.loads.Add(1)) - 1
+	return r.secrets[min(i, len(r.secrets)-1)], nil
+}
+
+func TestRefreshRejectedSecret(t *testing.T) {
+	s := newMessageGateway(t, nil)
+	provider := &rotatingSecret{secrets: []Secret{"old", "secret"}}
+
+	var logs bytes.Buffer
+	td := &TEDApi{BaseURL: s.URL, SecretProvider: provider, Logger: slog.New(slog.NewTextHandler(&logs, nil))}
+	if din, err := td.LeaderDIN(context.Background()); err != nil || din != fakeLeaderDIN {
+		t.Fatalf("got %q, %v", din, err)
+	}
+	if got := provider.loads.Load(); got != 2 {
+		t.Errorf("got %d loads, want 2", got)
+	}
+	if !strings.Contains(logs.String(), "secret was rejected") || strings.Contains(logs.String(), "old") {
+		t.Errorf("got logs %s", logs.String())
+	}
+}
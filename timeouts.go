@@ -0,0 +1,63 @@
+package powerwall
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+const (
+	DefaultConnectTimeout = 10 * time.Second // see [TEDApi.ConnectTimeout]
+	DefaultTLSTimeout     = 10 * time.Second // see [TEDApi.TLSTimeout]
+	DefaultTimeout        = 30 * time.Second // see [TEDApi.Timeout]
+)
+
+func (td *TEDApi) connectTimeout() time.Duration {
+	return orDefault(td.ConnectTimeout, DefaultConnectTimeout)
+}
+
+func (td *TEDApi) tlsTimeout() time.Duration {
+	return orDefault(td.TLSTimeout, DefaultTLSTimeout)
+}
+
+func (td *TEDApi) timeout() time.Duration {
+	return orDefault(td.Timeout, DefaultTimeout)
+}
+
+// lookupTimeout bounds a shared lookup (e.g., of the DIN), which isn't tied to any one caller's context.
+// It allows for a request to each remote.
+func (td *TEDApi) lookupTimeout() time.Duration {
+	return td.timeout() * time.Duration(len(td.remotes()))
+}
+
+func orDefault(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+	return def
+}
+
+// withConnectTimeout wraps dial (or a plain dialer, if nil) so each connection attempt is bounded by timeout.
+func withConnectTimeout(dial DialFunc, timeout time.Duration) DialFunc {
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return dial(ctx, network, addr)
+	}
+}
+
+// Budget returns a context for the next of parts remaining steps, with an equal share of ctx's remaining time.
+// Call it for each step of a multi-step operation with the number of steps left (including this one), so time a
+// step doesn't use rolls over to the rest, and one slow step (e.g., a dead follower) can't use up the whole deadline.
+// If ctx has no deadline, the returned context doesn't either.
+func Budget(ctx context.Context, parts int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || parts <= 1 {
+		return context.WithCancel(ctx)
+	}
+	share := time.Until(deadline) / time.Duration(parts)
+	return context.WithTimeout(ctx, share)
+}
@@ -0,0 +1,55 @@
+package powerwall
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBudget(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration // zero for no deadline
+		parts   int
+		want    time.Duration // zero for no deadline
+	}{
+		{"no deadline", 0, 4, 0},
+		{"one part", time.Minute, 1, time.Minute},
+		{"zero parts", time.Minute, 0, time.Minute},
+		{"two parts", time.Minute, 2, 30 * time.Second},
+		{"four parts", time.Minute, 4, 15 * time.Second},
+	}
+	for _, tt := range tests {
+		parent := context.Background()
+		if tt.timeout != 0 {
+			var cancel context.CancelFunc
+			parent, cancel = context.WithTimeout(parent, tt.timeout)
+			defer cancel()
+		}
+
+		ctx, cancel := Budget(parent, tt.parts)
+		deadline, ok := ctx.Deadline()
+		if tt.want == 0 {
+			if ok {
+				t.Errorf("%s: got deadline, want none", tt.name)
+			}
+		} else if got := time.Until(deadline); !ok || got > tt.want || got < tt.want-time.Second {
+			t.Errorf("%s: got %v left, want about %v", tt.name, got, tt.want)
+		}
+
+		// the step's context ends with its parent
+		cancel()
+		if ctx.Err() == nil {
+			t.Errorf("%s: not cancelled", tt.name)
+		}
+	}
+
+	// a cancelled parent cancels the step
+	parent, cancel := context.WithTimeout(context.Background(), time.Minute)
+	ctx, stepCancel := Budget(parent, 3)
+	defer stepCancel()
+	cancel()
+	if ctx.Err() == nil {
+		t.Errorf("step outlived its parent")
+	}
+}
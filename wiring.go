@@ -0,0 +1,97 @@
+package powerwall
+
+import (
+	"fmt"
+	"math"
+)
+
+// Wiring describes how a device or site is connected to the grid.
+type Wiring string
+
+const (
+	WiringUnknown     Wiring = ""             // no live voltages measured
+	WiringSinglePhase Wiring = "single-phase" // e.g., AU/EU homes, or one phase of a three-phase site
+	WiringSplitPhase  Wiring = "split-phase"  // e.g., US homes, two 120v legs 180° apart
+	WiringThreePhase  Wiring = "three-phase"
+)
+
+const (
+	// minLiveVoltage is the voltage below which a line is treated as not connected.
+	minLiveVoltage = 50.0
+
+	// maxSplitPhaseLeg is the highest line-to-neutral voltage expected of a split-phase leg (nominally 120v).
+	// Three-phase and single-phase sites outside North America are nominally 230v line-to-neutral.
+	maxSplitPhaseLeg = 160.0
+)
+
+// detectDeviceWiring works out how a device is wired from its voltages.
+// With both legs live, the line-to-line voltage tells split-phase (roughly their sum, as the legs are 180° apart)
+// from two phases of a three-phase supply (roughly √3 times either, as they're 120° apart).
+func detectDeviceWiring(an, bn, ab float64) Wiring {
+	liveA, liveB := an >= minLiveVoltage, bn >= minLiveVoltage
+	switch {
+	case !liveA && !liveB && ab < minLiveVoltage:
+		return WiringUnknown
+	case liveA && liveB && ab >= 0.9*(an+bn):
+		return WiringSplitPhase
+	case liveA && liveB && ab >= 0.9*math.Sqrt(3)*(an+bn)/2:
+		return WiringThreePhase
+	}
+	return WiringSinglePhase
+}
+
+// deviceVoltage picks the voltage that fits a device's wiring: line-to-line across the two legs of a split-phase
+// device, otherwise line-to-neutral (or line-to-line, if that's all that was measured).
+func deviceVoltage(w Wiring, an, bn, ab float64) float64 {
+	switch w {
+	case WiringSplitPhase:
+		return ab
+	case WiringSinglePhase, WiringThreePhase:
+		if v := max(an, bn); v >= minLiveVoltage {
+			return v
+		}
+		return ab
+	}
+	return 0
+}
+
+// formatVoltage describes a device's voltages in the way that fits its wiring.
+func formatVoltage(w Wiring, an, bn, ab float64) string {
+	switch w {
+	case WiringSplitPhase:
+		return fmt.Sprintf("%.1fv L-L (%.1fv + %.1fv L-N)", ab, an, bn)
+	case WiringThreePhase:
+		return fmt.Sprintf("%.1fv L-N (%.1fv L-L)", deviceVoltage(w, an, bn, ab), ab)
+	case WiringSinglePhase:
+		return fmt.Sprintf("%.1fv", deviceVoltage(w, an, bn, ab))
+	}
+	return "-"
+}
+
+// detectSiteWiring works out the site's wiring from its per-phase line-to-neutral voltages.
+// Two live legs near 120v are split-phase; two live phases near 230v are ambiguous (e.g., two of three measured), so
+// are reported as unknown.
+func detectSiteWiring(phases [3]SimplePhase) Wiring {
+	var live int
+	lowVoltage := true
+	for _, p := range phases {
+		v := max(p.VoltageMain, p.VoltageLoad)
+		if v >= minLiveVoltage {
+			live++
+			lowVoltage = lowVoltage && v <= maxSplitPhaseLeg
+		}
+	}
+
+	switch live {
+	case 0:
+		return WiringUnknown
+	case 1:
+		return WiringSinglePhase
+	case 2:
+		if lowVoltage {
+			return WiringSplitPhase
+		}
+		return WiringUnknown
+	}
+	return WiringThreePhase
+}
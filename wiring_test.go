@@ -0,0 +1,61 @@
+package powerwall
+
+import (
+	"testing"
+)
+
+func TestDeviceWiringVoltage(t *testing.T) {
+	tests := []struct {
+		name        string
+		an, bn, ab  float64
+		want        Wiring
+		wantVoltage float64
+		wantFormat  string
+	}{
+		{"dead", 0, 0, 0, WiringUnknown, 0, "-"},
+		{"split-phase", 120, 121, 241, WiringSplitPhase, 241, "241.0v L-L (120.0v + 121.0v L-N)"},
+		{"single-phase", 230, 0, 0, WiringSinglePhase, 230, "230.0v"},
+		{"single-phase on B", 0, 231, 0, WiringSinglePhase, 231, "231.0v"},
+		{"single-phase, line-to-line only", 0, 0, 240, WiringSinglePhase, 240, "240.0v"},
+		{"both legs on one phase", 230, 230, 0, WiringSinglePhase, 230, "230.0v"},
+		{"three-phase 400/230", 230, 230, 400, WiringThreePhase, 230, "230.0v L-N (400.0v L-L)"},
+		{"three-phase 208Y/120", 120, 120, 208, WiringThreePhase, 120, "120.0v L-N (208.0v L-L)"},
+	}
+	for _, tt := range tests {
+		got := detectDeviceWiring(tt.an, tt.bn, tt.ab)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+		if v := deviceVoltage(got, tt.an, tt.bn, tt.ab); v != tt.wantVoltage {
+			t.Errorf("%s: got voltage %v, want %v", tt.name, v, tt.wantVoltage)
+		}
+		if f := formatVoltage(got, tt.an, tt.bn, tt.ab); f != tt.wantFormat {
+			t.Errorf("%s: got %q, want %q", tt.name, f, tt.wantFormat)
+		}
+	}
+}
+
+func TestDetectSiteWiring(t *testing.T) {
+	phase := func(v float64) SimplePhase {
+		return SimplePhase{VoltageMain: v}
+	}
+	tests := []struct {
+		name   string
+		phases [3]SimplePhase
+		want   Wiring
+	}{
+		{"dead", [3]SimplePhase{}, WiringUnknown},
+		{"single-phase", [3]SimplePhase{phase(230)}, WiringSinglePhase},
+		{"split-phase", [3]SimplePhase{phase(120), phase(122)}, WiringSplitPhase},
+		{"split-phase, load side only", [3]SimplePhase{{VoltageLoad: 119}, {VoltageLoad: 121}}, WiringSplitPhase},
+		{"two 230v phases", [3]SimplePhase{phase(230), phase(231)}, WiringUnknown},
+		{"three-phase", [3]SimplePhase{phase(230), phase(231), phase(229)}, WiringThreePhase},
+		{"three-phase 208Y/120", [3]SimplePhase{phase(120), phase(120), phase(120)}, WiringThreePhase},
+	}
+	for _, tt := range tests {
+		got := detectSiteWiring(tt.phases)
+		if got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}